@@ -2,87 +2,341 @@ package main
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"qa-service/internal/auth"
+	"qa-service/internal/config"
 	"qa-service/internal/database"
 	"qa-service/internal/handlers"
+	"qa-service/internal/health"
+	"qa-service/internal/idempotency"
+	"qa-service/internal/logging"
+	"qa-service/internal/metrics"
+	"qa-service/internal/models"
+	"qa-service/internal/policy"
+	"qa-service/internal/ratelimit"
 	"qa-service/internal/repository"
 	"qa-service/internal/routes"
 	"qa-service/internal/services"
+	"qa-service/internal/tracing"
+	"qa-service/migrations"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
 
 func main() {
-	logger := log.New(os.Stdout, "[QA-SERVICE] ", log.LstdFlags)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "create-api-key":
+			createAPIKey(os.Args[2:])
+			return
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		case "schema":
+			runSchema(os.Args[2:])
+			return
+		}
+	}
 
-	logger.Println("Initializing database...")
-	if err := database.InitDB(); err != nil {
-		logger.Fatalf("Failed to initialize database: %v", err)
+	fs := flag.NewFlagSet("qa-service", flag.ExitOnError)
+	printConfig := fs.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	cfg := loadConfig(fs, os.Args[1:])
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-	defer func() {
-		if err := database.Close(); err != nil {
-			logger.Printf("Error closing database: %v", err)
+
+	logger := newLogger(cfg.Log)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "qa-service",
+	})
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
+	}
+
+	m := metrics.New()
+	var repos *repository.Repositories
+	var checks []health.Check
+
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Warn("using in-memory storage, data will not survive a restart")
+		repos = repository.NewMemoryRepositories(repository.NewMemoryStore())
+	case config.StoragePostgres:
+		logger.Info("initializing database")
+		if err := database.InitDB(cfg.Database, logger); err != nil {
+			fatal(logger, "failed to initialize database", err)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				logger.Error("error closing database", "error", err)
+			}
+		}()
+		if err := ensureSchema(context.Background(), cfg.Database.AutoMigrate, logger); err != nil {
+			fatal(logger, "database schema is not ready", err)
 		}
-	}()
 
-	questionRepo := repository.NewQuestionRepository(database.GetDB())
-	answerRepo := repository.NewAnswerRepository(database.GetDB())
+		if err := m.InstrumentDB(database.GetDB(), cfg.Database.Name); err != nil {
+			fatal(logger, "failed to instrument database", err)
+		}
+		if err := tracing.InstrumentDB(database.GetDB()); err != nil {
+			fatal(logger, "failed to instrument database", err)
+		}
+		repos = repository.NewRepositories(database.GetDB())
 
-	questionService := services.NewQuestionService(questionRepo)
-	answerService := services.NewAnswerService(answerRepo, questionRepo)
+		schemaVersion, err := migrations.Latest()
+		if err != nil {
+			fatal(logger, "failed to read embedded migrations", err)
+		}
+		db := database.GetDB()
+		checks = append(checks,
+			health.Check{Name: "database", Run: func(ctx context.Context) error { return database.Ping(ctx, db) }},
+			health.Check{Name: "migrations", Run: func(ctx context.Context) error { return database.CheckSchemaVersion(ctx, db, schemaVersion) }},
+		)
+	}
 
-	questionHandler := handlers.NewQuestionHandler(questionService, logger)
-	answerHandler := handlers.NewAnswerHandler(answerService, logger)
+	authz := policy.Default()
+	if path := cfg.Auth.PolicyFile; path != "" {
+		var err error
+		if authz, err = policy.Load(path); err != nil {
+			fatal(logger, "failed to load policy", err)
+		}
+	}
 
-	router := routes.SetupRoutes(questionHandler, answerHandler, logger)
+	questionService := services.NewQuestionService(repos.Questions, repos.Answers, repos.Revisions, authz, m)
+	answerService := services.NewAnswerService(repos.Answers, repos.Questions, repos.Revisions, authz, m)
+	searchService := services.NewSearchService(repos.Search)
+	voteService := services.NewVoteService(repos.Votes)
+	tagService := services.NewTagService(repos.Tags)
+	apiKeyService := services.NewAPIKeyService(repos.APIKeys)
+	commentService := services.NewCommentService(repos.Comments, repos.Questions, repos.Answers, authz)
+	trashService := services.NewTrashService(repos.Trash)
+
+	authenticator := auth.NewAuthenticator(newJWTVerifier(cfg.Auth, logger), apiKeyService)
+
+	probe := health.NewProbe(cfg.Server.ReadinessTimeout, checks...)
+
+	handlerSet := routes.Handlers{
+		Questions: handlers.NewQuestionHandler(questionService, logger),
+		Answers:   handlers.NewAnswerHandler(answerService, logger),
+		Search:    handlers.NewSearchHandler(searchService, logger),
+		Votes:     handlers.NewVoteHandler(voteService, logger),
+		Tags:      handlers.NewTagHandler(tagService, logger),
+		APIKeys:   handlers.NewAPIKeyHandler(apiKeyService, logger),
+		Comments:  handlers.NewCommentHandler(commentService, logger),
+		Trash:     handlers.NewTrashHandler(trashService, logger),
+		Health:    handlers.NewHealthHandler(probe, logger),
+	}
+
+	timeouts := routes.Timeouts{Default: cfg.Server.RequestTimeout, Routes: cfg.Server.RouteTimeouts}
+	routeMetrics := m
+	if !cfg.Features.Metrics {
+		routeMetrics = nil
+	}
+	rateLimit := routes.RateLimit{TrustedProxies: cfg.RateLimit.TrustedProxies}
+	if cfg.Features.RateLimit {
+		var store ratelimit.Store = ratelimit.NewMemoryStore(nil)
+		if cfg.RateLimit.Store == config.StoragePostgres {
+			store = ratelimit.NewPostgresStore(database.GetDB())
+		}
+		rateLimit.Limiter = ratelimit.NewLimiter(store, cfg.RateLimit.Policies)
+	}
+	idempotent := routes.Idempotency{TTL: cfg.Idempotency.TTL, LockTimeout: cfg.Idempotency.LockTimeout}
+	if cfg.Features.Idempotency {
+		idempotent.Store = idempotency.NewMemoryStore(nil)
+		if cfg.Idempotency.Store == config.StoragePostgres {
+			idempotent.Store = idempotency.NewPostgresStore(database.GetDB())
+		}
+	}
+	router := routes.SetupRoutes(handlerSet, authenticator, authz, timeouts, rateLimit, idempotent, routeMetrics, logger)
+
+	// Requests derive their context from requestCtx, so cancelling it aborts
+	// queries that are still running when the shutdown grace period ends.
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
-	port := getEnv("PORT", "8080")
 	server := &http.Server{
-		Addr:         ":" + port,
+		Addr:         ":" + strconv.Itoa(cfg.Server.Port),
 		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return requestCtx },
+	}
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if cfg.Features.TrashPurge {
+		go runTrashPurge(purgeCtx, trashService, cfg.Trash.PurgeInterval, cfg.Trash.Retention, logger)
 	}
 
 	go func() {
-		logger.Printf("Starting server on port %s", port)
+		logger.Info("starting server", "port", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("Server failed to start: %v", err)
+			fatal(logger, "server failed to start", err)
 		}
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	logger.Println("Shutting down server...")
 
-	shutdownTimeout := 30 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	// Fail readiness first and keep serving for a while, so load balancers
+	// stop sending traffic before the listener closes.
+	probe.Drain()
+	logger.Info("draining", "delay", cfg.Server.DrainDelay)
+	time.Sleep(cfg.Server.DrainDelay)
+
+	logger.Info("shutting down server")
+	stopPurge()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	shutdownChan := make(chan struct{})
 	go func() {
 		if err := server.Shutdown(ctx); err != nil {
-			logger.Printf("Server shutdown error: %v", err)
+			logger.Error("server shutdown error, cancelling in-flight requests", "error", err)
+			cancelRequests()
 		}
 		close(shutdownChan)
 	}()
 
 	select {
 	case <-shutdownChan:
-		logger.Println("Server shutdown complete")
-	case <-time.After(shutdownTimeout):
-		logger.Println("Server shutdown timeout")
+		logger.Info("server shutdown complete")
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		logger.Warn("server shutdown timeout")
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error("error flushing traces", "error", err)
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// runTrashPurge permanently removes trashed content older than retention,
+// once at startup and then every interval.
+func runTrashPurge(ctx context.Context, trashService *services.TrashService, interval, retention time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := trashService.Purge(ctx, retention)
+		if err != nil {
+			logger.Error("error purging trash", "error", err)
+		} else if result.Questions > 0 || result.Answers > 0 {
+			logger.Info("purged trash", "questions", result.Questions, "answers", result.Answers)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadConfig reads the configuration or exits with every problem found.
+func loadConfig(fs *flag.FlagSet, args []string) *config.Config {
+	cfg, err := config.Load(fs, args, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return cfg
+}
+
+func newLogger(cfg config.LogConfig) *slog.Logger {
+	logger, err := logging.New(os.Stdout, cfg.Format, cfg.Level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging configuration: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+	return logger
+}
+
+// newJWTVerifier loads the configured JWT signing keys. Without any keys
+// only API keys are accepted.
+func newJWTVerifier(cfg config.AuthConfig, logger *slog.Logger) *auth.JWTVerifier {
+	keys := auth.NewKeySet()
+	if path := cfg.JWTSecretFile; path != "" {
+		if err := keys.LoadHMACFile(path); err != nil {
+			fatal(logger, "failed to load JWT secret", err)
+		}
+	}
+	if path := cfg.JWTPublicKeyFile; path != "" {
+		if err := keys.LoadRSAPublicKeyFile(path); err != nil {
+			fatal(logger, "failed to load JWT public key", err)
+		}
+	}
+	if path := cfg.JWKSFile; path != "" {
+		if err := keys.LoadJWKSFile(path); err != nil {
+			fatal(logger, "failed to load JWKS", err)
+		}
+	}
+
+	if keys.Empty() {
+		logger.Warn("no JWT keys configured, only API keys will be accepted")
+		return nil
 	}
-	return defaultValue
+	return auth.NewJWTVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience)
+}
+
+// createAPIKey issues an API key from the command line, which is how the
+// first admin key is created.
+func createAPIKey(args []string) {
+	fs := flag.NewFlagSet("create-api-key", flag.ExitOnError)
+	userID := fs.String("user", "", "user the key authenticates as")
+	name := fs.String("name", "", "label for the key")
+	roles := fs.String("roles", auth.RoleUser, "comma separated roles")
+	cfg := loadConfig(fs, args)
+	logger := newLogger(cfg.Log)
+
+	if err := database.InitDB(cfg.Database, logger); err != nil {
+		fatal(logger, "failed to initialize database", err)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			logger.Error("error closing database", "error", err)
+		}
+	}()
+
+	apiKeyService := services.NewAPIKeyService(repository.NewAPIKeyRepository(database.GetDB()))
+	key, err := apiKeyService.CreateAPIKey(context.Background(), &models.CreateAPIKeyRequest{
+		UserID: *userID,
+		Name:   *name,
+		Roles:  strings.Split(*roles, ","),
+	})
+	if err != nil {
+		fatal(logger, "failed to create API key", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(key); err != nil {
+		fatal(logger, "error encoding API key", err)
+	}
+}
+
+// fatal logs err and exits, like log.Fatal. Deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"qa-service/internal/database"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = `Usage: qa-service migrate <command> [flags]
+
+Commands:
+  up       apply all pending migrations
+  down     roll back the latest migration, or down to --to
+  redo     roll back the latest migration and apply it again
+  status   list migrations and when they were applied
+  version  print the current schema version
+
+Flags:
+`
+
+// runMigrate implements the migrate subcommand with the migrations embedded
+// in the binary.
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	to := fs.Int64("to", -1, "with down, roll back every migration newer than this version")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	command := args[0]
+	cfg := loadConfig(fs, args[1:])
+	logger := newLogger(cfg.Log)
+
+	if err := database.InitDB(cfg.Database, logger); err != nil {
+		fatal(logger, "failed to initialize database", err)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			logger.Error("error closing database", "error", err)
+		}
+	}()
+
+	migrator, err := database.NewMigrator(database.GetDB())
+	if err != nil {
+		fatal(logger, "failed to create migrator", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		results, err := migrator.Up(ctx)
+		printResults(results...)
+		if err != nil {
+			fatal(logger, "migration failed", err)
+		}
+	case "down":
+		if *to >= 0 {
+			results, err := migrator.DownTo(ctx, *to)
+			printResults(results...)
+			if err != nil {
+				fatal(logger, "migration failed", err)
+			}
+			break
+		}
+		result, err := migrator.Down(ctx)
+		printResults(result)
+		if err != nil {
+			fatal(logger, "migration failed", err)
+		}
+	case "redo":
+		down, err := migrator.Down(ctx)
+		printResults(down)
+		if err != nil {
+			fatal(logger, "migration failed", err)
+		}
+		up, err := migrator.UpByOne(ctx)
+		printResults(up)
+		if err != nil {
+			fatal(logger, "migration failed", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fatal(logger, "failed to read migration status", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, s := range statuses {
+			appliedAt := "-"
+			if s.State == goose.StateApplied {
+				appliedAt = s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+		}
+		if err := w.Flush(); err != nil {
+			fatal(logger, "error writing migration status", err)
+		}
+	case "version":
+		version, err := migrator.GetDBVersion(ctx)
+		if err != nil {
+			fatal(logger, "failed to read schema version", err)
+		}
+		fmt.Println(version)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n", command)
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func printResults(results ...*goose.MigrationResult) {
+	for _, result := range results {
+		if result != nil {
+			fmt.Println(result)
+		}
+	}
+}
+
+// ensureSchema applies pending migrations when autoMigrate is set and
+// otherwise refuses to run against a schema older than this build.
+func ensureSchema(ctx context.Context, autoMigrate bool, logger *slog.Logger) error {
+	migrator, err := database.NewMigrator(database.GetDB())
+	if err != nil {
+		return err
+	}
+
+	if !autoMigrate {
+		pending, err := migrator.HasPending(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if pending {
+			return errors.New(`database schema is behind this build, run "qa-service migrate up" or set database.auto_migrate`)
+		}
+		return nil
+	}
+
+	results, err := migrator.Up(ctx)
+	for _, result := range results {
+		logger.Info("applied migration", "version", result.Source.Version, "file", result.Source.Path, "duration", result.Duration)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
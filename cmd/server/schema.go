@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"qa-service/internal/database"
+	"qa-service/internal/models"
+	"qa-service/internal/schemacheck"
+)
+
+const schemaUsage = `Usage: qa-service schema check [flags]
+
+Compares the database schema with the models and lists every missing or
+extra column, type mismatch, missing index and foreign key action that
+differs. Exits with status 1 if there is any drift.
+
+Flags:
+`
+
+// runSchema implements the schema subcommand.
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), schemaUsage)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "check" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg := loadConfig(fs, args[1:])
+	logger := newLogger(cfg.Log)
+
+	if err := database.InitDB(cfg.Database, logger); err != nil {
+		fatal(logger, "failed to initialize database", err)
+	}
+	drifts, err := schemacheck.Check(context.Background(), database.GetDB(), models.All()...)
+	if cerr := database.Close(); cerr != nil {
+		logger.Error("error closing database", "error", cerr)
+	}
+	if err != nil {
+		fatal(logger, "failed to check schema", err)
+	}
+
+	for _, drift := range drifts {
+		fmt.Println(drift)
+	}
+	if len(drifts) > 0 {
+		fmt.Printf("%d differences between the models and the database\n", len(drifts))
+		os.Exit(1)
+	}
+	fmt.Println("schema matches the models")
+}
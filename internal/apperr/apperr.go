@@ -0,0 +1,101 @@
+// Package apperr holds the errors services return to their callers. The kind
+// of an error decides how it is reported, its code lets clients tell errors
+// apart without parsing messages.
+package apperr
+
+import "errors"
+
+// Kinds of errors. Match them with errors.Is.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrValidation    = errors.New("validation failed")
+	ErrConflict      = errors.New("conflict")
+	ErrUnprocessable = errors.New("unprocessable")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrUnavailable   = errors.New("unavailable")
+	ErrTimeout       = errors.New("timeout")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrPrecondition  = errors.New("precondition failed")
+)
+
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError reports one invalid request field. Rule names the check that
+// failed, such as "required" or "max".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message}
+}
+
+// InvalidFields is a validation error listing every field that failed.
+func InvalidFields(fields []FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: "validation_failed", Message: "request validation failed", Fields: fields}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// Unprocessable rejects a well-formed request that cannot be carried out as
+// sent.
+func Unprocessable(code, message string) *Error {
+	return &Error{Kind: ErrUnprocessable, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// Unavailable wraps a failure of a backing service, such as a lost database
+// connection, that is expected to go away on retry.
+func Unavailable(err error) *Error {
+	return &Error{Kind: ErrUnavailable, Code: "unavailable", Message: "service temporarily unavailable", Err: err}
+}
+
+// Timeout wraps an operation that ran out of time before it finished.
+func Timeout(err error) *Error {
+	return &Error{Kind: ErrTimeout, Code: "timeout", Message: "request took too long to process", Err: err}
+}
+
+// PreconditionFailed rejects a conditional request, such as one with
+// If-Match, whose condition no longer holds.
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: ErrPrecondition, Code: "precondition_failed", Message: message}
+}
+
+// RateLimited rejects a caller that sent too many requests.
+func RateLimited(message string) *Error {
+	return &Error{Kind: ErrRateLimited, Code: "rate_limited", Message: message}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// APIKeyLookup resolves the hash of a presented API key to its owner. It
+// returns ErrInvalidCredentials when the key is unknown or revoked.
+type APIKeyLookup interface {
+	LookupAPIKey(ctx context.Context, hash string) (*Principal, error)
+}
+
+type Authenticator struct {
+	jwt     *JWTVerifier
+	apiKeys APIKeyLookup
+}
+
+// NewAuthenticator builds an authenticator; jwt may be nil when no signing
+// keys are configured, in which case bearer tokens are rejected.
+func NewAuthenticator(jwt *JWTVerifier, apiKeys APIKeyLookup) *Authenticator {
+	return &Authenticator{
+		jwt:     jwt,
+		apiKeys: apiKeys,
+	}
+}
+
+// Authenticate returns the principal for the credentials carried by r, or nil
+// when the request carries none. Credentials are read from the X-API-Key
+// header or from "Authorization: Bearer", which accepts both JWTs and API keys.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.apiKey(r.Context(), key)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("%w: unsupported authorization header", ErrInvalidCredentials)
+	}
+	token = strings.TrimSpace(token)
+
+	if IsAPIKey(token) {
+		return a.apiKey(r.Context(), token)
+	}
+	if a.jwt == nil {
+		return nil, fmt.Errorf("%w: JWT authentication is not configured", ErrInvalidCredentials)
+	}
+	return a.jwt.Verify(token)
+}
+
+func (a *Authenticator) apiKey(ctx context.Context, key string) (*Principal, error) {
+	if a.apiKeys == nil || !IsAPIKey(key) {
+		return nil, ErrInvalidCredentials
+	}
+	return a.apiKeys.LookupAPIKey(ctx, HashAPIKey(key))
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTVerifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+type claims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// NewJWTVerifier accepts HS256 and RS256 tokens signed by one of keys.
+// Issuer and audience are only checked when they are not empty.
+func NewJWTVerifier(keys *KeySet, issuer, audience string) *JWTVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	return &JWTVerifier{
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+func (v *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &c, v.key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	roles := c.Roles
+	if len(roles) == 0 {
+		roles = []string{RoleUser}
+	}
+
+	return &Principal{
+		Subject: c.Subject,
+		Roles:   roles,
+		Method:  MethodJWT,
+	}, nil
+}
+
+func (v *JWTVerifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if secret, ok := v.keys.hmac[kid]; ok {
+			return secret, nil
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if key, ok := v.keys.rsa[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, errors.New("no matching key")
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// KeySet holds the keys accepted for JWT verification. Keys registered with a
+// key ID are matched against the token's "kid" header; keys without one are
+// used for tokens that do not carry a "kid".
+type KeySet struct {
+	hmac map[string][]byte
+	rsa  map[string]*rsa.PublicKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{
+		hmac: make(map[string][]byte),
+		rsa:  make(map[string]*rsa.PublicKey),
+	}
+}
+
+func (k *KeySet) AddHMAC(kid string, secret []byte) {
+	k.hmac[kid] = secret
+}
+
+func (k *KeySet) AddRSA(kid string, key *rsa.PublicKey) {
+	k.rsa[kid] = key
+}
+
+func (k *KeySet) Empty() bool {
+	return len(k.hmac) == 0 && len(k.rsa) == 0
+}
+
+// LoadHMACFile reads a shared HS256 secret. Surrounding whitespace is ignored
+// so that files written with a trailing newline work as expected.
+func (k *KeySet) LoadHMACFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return fmt.Errorf("%s: empty HMAC secret", path)
+	}
+	k.AddHMAC("", []byte(secret))
+	return nil
+}
+
+// LoadRSAPublicKeyFile reads a PEM encoded RS256 public key.
+func (k *KeySet) LoadRSAPublicKeyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%s: not an RSA public key", path)
+	}
+	k.AddRSA("", rsaKey)
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// LoadJWKSFile reads a JSON Web Key Set. RSA ("RSA") and symmetric ("oct")
+// signing keys are supported; other keys are skipped.
+func (k *KeySet) LoadJWKSFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	loaded := 0
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		switch key.Kty {
+		case "RSA":
+			pub, err := key.rsaPublicKey()
+			if err != nil {
+				return fmt.Errorf("%s: key %q: %w", path, key.Kid, err)
+			}
+			k.AddRSA(key.Kid, pub)
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.K, "="))
+			if err != nil || len(secret) == 0 {
+				return fmt.Errorf("%s: key %q: invalid symmetric key", path, key.Kid)
+			}
+			k.AddHMAC(key.Kid, secret)
+		default:
+			continue
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return fmt.Errorf("%s: no usable signing keys", path)
+	}
+	return nil
+}
+
+func (j jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(j.N, "="))
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(j.E, "="))
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+}
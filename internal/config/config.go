@@ -0,0 +1,265 @@
+// Package config holds the service configuration. Values come from
+// defaults, an optional YAML or TOML file, environment variables and
+// command-line flags, each overriding the previous.
+package config
+
+import (
+	"fmt"
+	"qa-service/internal/logging"
+	"qa-service/internal/ratelimit"
+	"qa-service/internal/tracing"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	StorageMemory   = "memory"
+	StoragePostgres = "postgres"
+)
+
+type Config struct {
+	Storage     string            `yaml:"storage" toml:"storage" env:"STORAGE_BACKEND" usage:"storage backend: postgres or memory"`
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	Database    DatabaseConfig    `yaml:"database" toml:"database"`
+	Log         LogConfig         `yaml:"log" toml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+	Auth        AuthConfig        `yaml:"auth" toml:"auth"`
+	Trash       TrashConfig       `yaml:"trash" toml:"trash"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency"`
+	Features    FeaturesConfig    `yaml:"features" toml:"features"`
+}
+
+type ServerConfig struct {
+	Port             int           `yaml:"port" toml:"port" env:"PORT" usage:"HTTP port"`
+	ReadTimeout      time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT" usage:"maximum time to read a request"`
+	WriteTimeout     time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" usage:"maximum time to write a response"`
+	IdleTimeout      time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" usage:"how long idle keep-alive connections stay open"`
+	ShutdownTimeout  time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"grace period for in-flight requests on shutdown"`
+	DrainDelay       time.Duration `yaml:"drain_delay" toml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY" usage:"how long to report not ready before shutting down"`
+	RequestTimeout   time.Duration `yaml:"request_timeout" toml:"request_timeout" env:"REQUEST_TIMEOUT" usage:"default deadline of a request, 0 disables it"`
+	RouteTimeouts    RouteTimeouts `yaml:"route_timeouts" toml:"route_timeouts" env:"ROUTE_TIMEOUTS" usage:"per-route deadlines as \"METHOD /path=duration,...\""`
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" toml:"readiness_timeout" env:"READINESS_TIMEOUT" usage:"timeout of each readiness check"`
+}
+
+// DatabaseConfig names the database either with a full DSN or with its
+// parts; DSN wins when both are set.
+type DatabaseConfig struct {
+	DSN                string        `yaml:"dsn" toml:"dsn" env:"DATABASE_URL" secret:"true" usage:"PostgreSQL connection string, overrides the other connection settings"`
+	Host               string        `yaml:"host" toml:"host" env:"DB_HOST" usage:"database host"`
+	Port               int           `yaml:"port" toml:"port" env:"DB_PORT" usage:"database port"`
+	User               string        `yaml:"user" toml:"user" env:"DB_USER" usage:"database user"`
+	Password           string        `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true" usage:"database password"`
+	Name               string        `yaml:"name" toml:"name" env:"DB_NAME" usage:"database name"`
+	SSLMode            string        `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE" usage:"PostgreSQL sslmode"`
+	MaxOpenConns       int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum open connections, 0 is unlimited"`
+	MaxIdleConns       int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum idle connections"`
+	ConnMaxLifetime    time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"maximum age of a connection, 0 is unlimited"`
+	ConnMaxIdleTime    time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"maximum idle time of a connection, 0 is unlimited"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" toml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" usage:"log queries slower than this as warnings, 0 disables it"`
+	AutoMigrate        bool          `yaml:"auto_migrate" toml:"auto_migrate" env:"DB_AUTO_MIGRATE" usage:"apply pending migrations on startup instead of refusing to start"`
+}
+
+type LogConfig struct {
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT" usage:"log format: json or text"`
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" usage:"minimum log level: debug, info, warn or error"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER" usage:"trace exporter: none, stdout or otlp"`
+	File        string  `yaml:"file" toml:"file" env:"TRACING_FILE" usage:"file the stdout exporter writes to instead of stdout"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"share of new traces that are recorded"`
+}
+
+type AuthConfig struct {
+	JWTSecretFile    string `yaml:"jwt_secret_file" toml:"jwt_secret_file" env:"AUTH_JWT_SECRET_FILE" usage:"file with the HMAC secret for JWTs"`
+	JWTPublicKeyFile string `yaml:"jwt_public_key_file" toml:"jwt_public_key_file" env:"AUTH_JWT_PUBLIC_KEY_FILE" usage:"PEM file with the RSA public key for JWTs"`
+	JWKSFile         string `yaml:"jwks_file" toml:"jwks_file" env:"AUTH_JWKS_FILE" usage:"JWKS file with keys for JWTs"`
+	JWTIssuer        string `yaml:"jwt_issuer" toml:"jwt_issuer" env:"AUTH_JWT_ISSUER" usage:"required JWT issuer"`
+	JWTAudience      string `yaml:"jwt_audience" toml:"jwt_audience" env:"AUTH_JWT_AUDIENCE" usage:"required JWT audience"`
+	PolicyFile       string `yaml:"policy_file" toml:"policy_file" env:"POLICY_FILE" usage:"JSON file with the access policy"`
+}
+
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention" env:"TRASH_RETENTION" usage:"how long deleted content stays restorable"`
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval" env:"TRASH_PURGE_INTERVAL" usage:"how often expired trash is purged"`
+}
+
+type RateLimitConfig struct {
+	Store          string            `yaml:"store" toml:"store" env:"RATE_LIMIT_STORE" usage:"where buckets are kept: memory, or postgres to share limits between replicas"`
+	Policies       RateLimitPolicies `yaml:"policies" toml:"policies" env:"RATE_LIMITS" usage:"token bucket per route group as \"group=limit/period[:burst],...\"; groups are read, write and create"`
+	TrustedProxies Prefixes          `yaml:"trusted_proxies" toml:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"addresses or networks of proxies whose X-Forwarded-For is trusted"`
+}
+
+type IdempotencyConfig struct {
+	Store       string        `yaml:"store" toml:"store" env:"IDEMPOTENCY_STORE" usage:"where idempotency keys are kept: memory, or postgres to recognize retries on every replica"`
+	TTL         time.Duration `yaml:"ttl" toml:"ttl" env:"IDEMPOTENCY_TTL" usage:"how long responses are kept for retries"`
+	LockTimeout time.Duration `yaml:"lock_timeout" toml:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT" usage:"how long a request in progress blocks retries with the same key"`
+}
+
+type FeaturesConfig struct {
+	Metrics     bool `yaml:"metrics" toml:"metrics" env:"FEATURE_METRICS" usage:"serve Prometheus metrics on /metrics"`
+	TrashPurge  bool `yaml:"trash_purge" toml:"trash_purge" env:"FEATURE_TRASH_PURGE" usage:"purge expired trash in the background"`
+	RateLimit   bool `yaml:"rate_limit" toml:"rate_limit" env:"FEATURE_RATE_LIMIT" usage:"limit how many requests each caller may make"`
+	Idempotency bool `yaml:"idempotency" toml:"idempotency" env:"FEATURE_IDEMPOTENCY" usage:"replay responses to create requests retried with the same Idempotency-Key"`
+}
+
+func Default() *Config {
+	return &Config{
+		Storage: StoragePostgres,
+		Server: ServerConfig{
+			Port:             8080,
+			ReadTimeout:      15 * time.Second,
+			WriteTimeout:     15 * time.Second,
+			IdleTimeout:      60 * time.Second,
+			ShutdownTimeout:  30 * time.Second,
+			DrainDelay:       5 * time.Second,
+			RequestTimeout:   10 * time.Second,
+			ReadinessTimeout: 2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               5432,
+			User:               "postgres",
+			Password:           "password",
+			Name:               "qa_service",
+			SSLMode:            "disable",
+			MaxOpenConns:       25,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    30 * time.Minute,
+			ConnMaxIdleTime:    5 * time.Minute,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Log: LogConfig{
+			Format: logging.FormatJSON,
+			Level:  "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Store: StorageMemory,
+			Policies: RateLimitPolicies{
+				ratelimit.GroupRead:   {Limit: 300, Period: time.Minute, Burst: 300},
+				ratelimit.GroupWrite:  {Limit: 60, Period: time.Minute, Burst: 60},
+				ratelimit.GroupCreate: {Limit: 10, Period: time.Minute, Burst: 5},
+			},
+		},
+		Idempotency: IdempotencyConfig{
+			Store:       StorageMemory,
+			TTL:         24 * time.Hour,
+			LockTimeout: time.Minute,
+		},
+		Features: FeaturesConfig{
+			Metrics:     true,
+			TrashPurge:  true,
+			RateLimit:   true,
+			Idempotency: true,
+		},
+	}
+}
+
+// ConnectionString returns the DSN for the database.
+func (c DatabaseConfig) ConnectionString() string {
+	if c.DSN != "" {
+		return c.DSN
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+// Validate reports every invalid setting at once, each prefixed with its
+// key, such as "server.port: must be between 1 and 65535".
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Storage == StoragePostgres || c.Storage == StorageMemory, "storage", "must be %s or %s, got %q", StoragePostgres, StorageMemory, c.Storage)
+
+	s := c.Server
+	check(s.Port >= 1 && s.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", s.Port)
+	check(s.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(s.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(s.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(s.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(s.DrainDelay >= 0, "server.drain_delay", "must not be negative")
+	check(s.RequestTimeout >= 0, "server.request_timeout", "must not be negative")
+	check(s.ReadinessTimeout > 0, "server.readiness_timeout", "must be positive")
+	for route, d := range s.RouteTimeouts {
+		check(d >= 0, "server.route_timeouts", "%s must not be negative", route)
+	}
+
+	if c.Storage == StoragePostgres {
+		d := c.Database
+		if d.DSN == "" {
+			check(d.Host != "", "database.host", "is required")
+			check(d.Port >= 1 && d.Port <= 65535, "database.port", "must be between 1 and 65535, got %d", d.Port)
+			check(d.User != "", "database.user", "is required")
+			check(d.Name != "", "database.name", "is required")
+		}
+		check(d.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+		check(d.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+		check(d.MaxOpenConns == 0 || d.MaxIdleConns <= d.MaxOpenConns, "database.max_idle_conns", "must not exceed database.max_open_conns (%d)", d.MaxOpenConns)
+		check(d.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+		check(d.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+		check(d.SlowQueryThreshold >= 0, "database.slow_query_threshold", "must not be negative")
+	}
+
+	check(c.Log.Format == logging.FormatJSON || c.Log.Format == logging.FormatText, "log.format", "must be %s or %s, got %q", logging.FormatJSON, logging.FormatText, c.Log.Format)
+	_, err := logging.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+
+	t := c.Tracing
+	check(t.Exporter == tracing.ExporterNone || t.Exporter == tracing.ExporterStdout || t.Exporter == tracing.ExporterOTLP,
+		"tracing.exporter", "must be %s, %s or %s, got %q", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, t.Exporter)
+	check(t.SampleRatio >= 0 && t.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %v", t.SampleRatio)
+	check(t.File == "" || t.Exporter == tracing.ExporterStdout, "tracing.file", "is only used by the %s exporter", tracing.ExporterStdout)
+
+	check(c.Trash.Retention > 0, "trash.retention", "must be positive")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval", "must be positive")
+
+	r := c.RateLimit
+	check(r.Store == StorageMemory || r.Store == StoragePostgres, "rate_limit.store", "must be %s or %s, got %q", StorageMemory, StoragePostgres, r.Store)
+	check(r.Store != StoragePostgres || c.Storage == StoragePostgres, "rate_limit.store", "%s requires the %s storage backend", StoragePostgres, StoragePostgres)
+	for group := range r.Policies {
+		check(slices.Contains(ratelimit.Groups, group), "rate_limit.policies", "unknown group %q, expected one of %s", group, strings.Join(ratelimit.Groups, ", "))
+	}
+
+	i := c.Idempotency
+	check(i.Store == StorageMemory || i.Store == StoragePostgres, "idempotency.store", "must be %s or %s, got %q", StorageMemory, StoragePostgres, i.Store)
+	check(i.Store != StoragePostgres || c.Storage == StoragePostgres, "idempotency.store", "%s requires the %s storage backend", StoragePostgres, StoragePostgres)
+	check(i.TTL > 0, "idempotency.ttl", "must be positive")
+	check(i.LockTimeout > 0, "idempotency.lock_timeout", "must be positive")
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidationError lists every invalid setting.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = "  " + err.Error()
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"qa-service/internal/ratelimit"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RateLimitPolicies sets the token bucket of each route group, keyed by
+// group name. Files may give it as a table or, like the environment and
+// flags, as a comma separated list of "group=limit/period[:burst]" entries.
+// Groups left out keep the policy they had.
+type RateLimitPolicies map[string]ratelimit.Policy
+
+func (p *RateLimitPolicies) UnmarshalText(text []byte) error {
+	entries, err := splitEntries(string(text))
+	if err != nil {
+		return err
+	}
+	return p.fromEntries(entries)
+}
+
+func (p *RateLimitPolicies) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return p.UnmarshalText([]byte(node.Value))
+	}
+	var entries map[string]string
+	if err := node.Decode(&entries); err != nil {
+		return err
+	}
+	return p.fromEntries(entries)
+}
+
+func (p *RateLimitPolicies) UnmarshalTOML(data interface{}) error {
+	switch data := data.(type) {
+	case string:
+		return p.UnmarshalText([]byte(data))
+	case map[string]interface{}:
+		entries := make(map[string]string, len(data))
+		for group, value := range data {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("rate limit of %q must be a string such as \"10/m:5\"", group)
+			}
+			entries[group] = s
+		}
+		return p.fromEntries(entries)
+	}
+	return fmt.Errorf("rate limits must be a table or a string, got %T", data)
+}
+
+func (p *RateLimitPolicies) fromEntries(entries map[string]string) error {
+	parsed := make(RateLimitPolicies, len(*p)+len(entries))
+	for group, policy := range *p {
+		parsed[group] = policy
+	}
+	for group, value := range entries {
+		policy, err := ratelimit.ParsePolicy(value)
+		if err != nil {
+			return fmt.Errorf("group %q: %w", group, err)
+		}
+		parsed[strings.ToLower(strings.TrimSpace(group))] = policy
+	}
+	*p = parsed
+	return nil
+}
+
+// ParseRateLimitPolicies reads policies written as a comma separated list of
+// "group=limit/period[:burst]" entries.
+func ParseRateLimitPolicies(spec string) (RateLimitPolicies, error) {
+	entries, err := splitEntries(spec)
+	if err != nil {
+		return nil, err
+	}
+	var policies RateLimitPolicies
+	if err := policies.fromEntries(entries); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func splitEntries(spec string) (map[string]string, error) {
+	entries := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q, expected \"group=limit/period[:burst]\"", entry)
+		}
+		entries[group] = value
+	}
+	return entries, nil
+}
+
+// Prefixes is a list of networks. Files may give it as a list or, like the
+// environment and flags, as a comma separated string; a bare address stands
+// for itself.
+type Prefixes []netip.Prefix
+
+func (p *Prefixes) UnmarshalText(text []byte) error {
+	return p.fromEntries(strings.Split(string(text), ","))
+}
+
+func (p *Prefixes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return p.UnmarshalText([]byte(node.Value))
+	}
+	var entries []string
+	if err := node.Decode(&entries); err != nil {
+		return err
+	}
+	return p.fromEntries(entries)
+}
+
+func (p *Prefixes) UnmarshalTOML(data interface{}) error {
+	switch data := data.(type) {
+	case string:
+		return p.UnmarshalText([]byte(data))
+	case []interface{}:
+		entries := make([]string, len(data))
+		for i, value := range data {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("network %v must be a string", value)
+			}
+			entries[i] = s
+		}
+		return p.fromEntries(entries)
+	}
+	return fmt.Errorf("networks must be an array or a string, got %T", data)
+}
+
+func (p *Prefixes) fromEntries(entries []string) error {
+	parsed := Prefixes{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return fmt.Errorf("invalid address %q", entry)
+			}
+			addr = addr.Unmap()
+			parsed = append(parsed, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return fmt.Errorf("invalid network %q", entry)
+		}
+		parsed = append(parsed, prefix.Masked())
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RouteTimeouts overrides the request deadline of single routes, keyed by
+// method and path template such as "GET /api/v1/questions/{id}". Files may
+// give it as a table or, like the environment and flags, as a comma
+// separated list of "METHOD /path=duration" entries.
+type RouteTimeouts map[string]time.Duration
+
+func (t *RouteTimeouts) UnmarshalText(text []byte) error {
+	parsed, err := ParseRouteTimeouts(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+func (t *RouteTimeouts) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return t.UnmarshalText([]byte(node.Value))
+	}
+	var entries map[string]string
+	if err := node.Decode(&entries); err != nil {
+		return err
+	}
+	return t.fromEntries(entries)
+}
+
+func (t *RouteTimeouts) UnmarshalTOML(data interface{}) error {
+	switch data := data.(type) {
+	case string:
+		return t.UnmarshalText([]byte(data))
+	case map[string]interface{}:
+		entries := make(map[string]string, len(data))
+		for route, value := range data {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("route timeout %q must be a duration string", route)
+			}
+			entries[route] = s
+		}
+		return t.fromEntries(entries)
+	}
+	return fmt.Errorf("route timeouts must be a table or a string, got %T", data)
+}
+
+func (t *RouteTimeouts) fromEntries(entries map[string]string) error {
+	parsed := make(RouteTimeouts, len(entries))
+	for route, value := range entries {
+		key, d, err := parseRouteTimeout(route, value)
+		if err != nil {
+			return err
+		}
+		parsed[key] = d
+	}
+	*t = parsed
+	return nil
+}
+
+// ParseRouteTimeouts reads overrides written as a comma separated list of
+// "METHOD /path=duration" entries.
+func ParseRouteTimeouts(spec string) (RouteTimeouts, error) {
+	timeouts := make(RouteTimeouts)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route timeout %q, expected \"METHOD /path=duration\"", entry)
+		}
+		key, d, err := parseRouteTimeout(route, value)
+		if err != nil {
+			return nil, err
+		}
+		timeouts[key] = d
+	}
+	return timeouts, nil
+}
+
+func parseRouteTimeout(route, value string) (string, time.Duration, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid route %q, expected \"METHOD /path\"", route)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d < 0 {
+		return "", 0, fmt.Errorf("invalid duration %q for route %q", value, route)
+	}
+	return strings.ToUpper(method) + " " + strings.TrimSpace(path), d, nil
+}
@@ -1,44 +1,41 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
-	"qa-service/internal/models"
+	"qa-service/internal/config"
 )
 
 var DB *gorm.DB
 
-func InitDB() error {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "postgres")
-	password := getEnv("DB_PASSWORD", "password")
-	dbname := getEnv("DB_NAME", "qa_service")
-	sslmode := getEnv("DB_SSLMODE", "disable")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		host, port, user, password, dbname, sslmode)
-
+// InitDB connects to PostgreSQL and configures the connection pool. It
+// leaves the schema alone, migrations are applied with NewMigrator. SQL is
+// logged to logger, with statements slower than cfg.SlowQueryThreshold
+// logged as warnings.
+func InitDB(cfg config.DatabaseConfig, logger *slog.Logger) error {
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	DB, err = gorm.Open(postgres.Open(cfg.ConnectionString()), &gorm.Config{
+		Logger: NewLogger(logger, cfg.SlowQueryThreshold),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	err = DB.AutoMigrate(&models.Question{}, &models.Answer{})
+	sqlDB, err := DB.DB()
 	if err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+		return fmt.Errorf("failed to configure connection pool: %w", err)
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	log.Println("Database connected and migrated successfully")
+	logger.Info("database connected")
 	return nil
 }
 
@@ -54,9 +51,27 @@ func Close() error {
 	return sqlDB.Close()
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Ping checks that a connection to the database can be used.
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
 	}
-	return defaultValue
+	return sqlDB.PingContext(ctx)
+}
+
+// CheckSchemaVersion fails unless goose has applied every migration up to
+// expected.
+func CheckSchemaVersion(ctx context.Context, db *gorm.DB, expected int64) error {
+	var current int64
+	err := db.WithContext(ctx).
+		Raw("SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied").
+		Scan(&current).Error
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if current < expected {
+		return fmt.Errorf("schema version %d is behind %d", current, expected)
+	}
+	return nil
 }
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryLogger sends GORM's output to slog. Statements are logged at debug
+// level, statements slower than slowThreshold as warnings and failed ones as
+// errors. The request ID is added by the slog handler from ctx.
+type queryLogger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewLogger returns a GORM logger writing to l. A zero slowThreshold
+// disables slow query warnings.
+func NewLogger(l *slog.Logger, slowThreshold time.Duration) logger.Interface {
+	return &queryLogger{logger: l, level: logger.Info, slowThreshold: slowThreshold}
+}
+
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	var level slog.Level
+	var msg string
+	switch {
+	case failed && l.level >= logger.Error:
+		level, msg = slog.LevelError, "query failed"
+	case slow && l.level >= logger.Warn:
+		level, msg = slog.LevelWarn, "slow query"
+	case l.level >= logger.Info:
+		level, msg = slog.LevelDebug, "query"
+	default:
+		return
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("duration", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"gorm.io/gorm"
+
+	"qa-service/migrations"
+)
+
+// NewMigrator returns a goose provider for the migrations embedded in the
+// binary. Up, down and status hold a PostgreSQL advisory lock, so when
+// several instances migrate at once one applies the migrations and the
+// others wait for it.
+func NewMigrator(db *gorm.DB) (*goose.Provider, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return provider, nil
+}
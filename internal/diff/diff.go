@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"unicode"
+)
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+type Change struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Words returns the changes turning a into b, computed over words and the
+// whitespace between them. Adjacent changes of the same kind are merged.
+func Words(a, b string) []Change {
+	x, y := tokenize(a), tokenize(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := make([]Change, 0)
+	add := func(op, text string) {
+		if n := len(changes); n > 0 && changes[n-1].Op == op {
+			changes[n-1].Text += text
+			return
+		}
+		changes = append(changes, Change{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			add(OpEqual, x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(OpDelete, x[i])
+			i++
+		default:
+			add(OpInsert, y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		add(OpDelete, x[i])
+	}
+	for ; j < len(y); j++ {
+		add(OpInsert, y[j])
+	}
+	return changes
+}
+
+func tokenize(s string) []string {
+	tokens := make([]string, 0)
+	start := 0
+	runes := []rune(s)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || unicode.IsSpace(runes[i]) != unicode.IsSpace(runes[start]) {
+			tokens = append(tokens, string(runes[start:i]))
+			start = i
+		}
+	}
+	return tokens
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"qa-service/internal/auth"
 	"qa-service/internal/models"
+	"qa-service/internal/problem"
 	"qa-service/internal/services"
+	"qa-service/internal/validation"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -13,10 +16,10 @@ import (
 
 type AnswerHandler struct {
 	answerService *services.AnswerService
-	logger        *log.Logger
+	logger        *slog.Logger
 }
 
-func NewAnswerHandler(answerService *services.AnswerService, logger *log.Logger) *AnswerHandler {
+func NewAnswerHandler(answerService *services.AnswerService, logger *slog.Logger) *AnswerHandler {
 	return &AnswerHandler{
 		answerService: answerService,
 		logger:        logger,
@@ -27,42 +30,71 @@ func (h *AnswerHandler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	questionIDStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "Question ID not provided", http.StatusBadRequest)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
 	questionID, err := strconv.ParseUint(questionIDStr, 10, 32)
 	if err != nil {
-		h.logger.Printf("Invalid question ID: %v", err)
-		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		h.logger.DebugContext(r.Context(), "invalid question ID", "error", err)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	h.logger.Printf("Handling POST /questions/%d/answers/", questionID)
-
 	var req models.CreateAnswerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Printf("Error decoding request: %v", err)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
 		return
 	}
 
-	answer, err := h.answerService.CreateAnswer(uint(questionID), &req)
+	answer, err := h.answerService.CreateAnswer(r.Context(), auth.FromContext(r.Context()), uint(questionID), &req)
 	if err != nil {
-		h.logger.Printf("Error creating answer: %v", err)
-		if err.Error() == "question not found" {
-			http.Error(w, "Question not found", http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		}
+		logError(r, h.logger, "error creating answer", err)
+		problem.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(answer); err != nil {
-		h.logger.Printf("Error encoding answer: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "error encoding answer", "error", err)
+		return
+	}
+}
+
+func (h *AnswerHandler) GetAnswers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	questionIDStr, exists := vars["id"]
+	if !exists {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	questionID, err := strconv.ParseUint(questionIDStr, 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid question ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	opts, err := parseAnswerListOptions(r.URL.Query(), "")
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid list parameters", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	page, err := h.answerService.ListAnswers(r.Context(), uint(questionID), opts)
+	if err != nil {
+		logError(r, h.logger, "error getting answers", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding answers", "error", err)
 		return
 	}
 }
@@ -71,30 +103,138 @@ func (h *AnswerHandler) GetAnswer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "ID not provided", http.StatusBadRequest)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		h.logger.Printf("Invalid ID: %v", err)
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	answer, err := h.answerService.GetAnswerByID(r.Context(), uint(id))
+	if err != nil {
+		logError(r, h.logger, "error getting answer", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	writeRepresentation(w, r, h.logger, answer, answer.Version, answerLastModified(answer))
+}
+
+func (h *AnswerHandler) UpdateAnswer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	h.logger.Printf("Handling GET /answers/%d", id)
+	var req models.UpdateAnswerRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
 
-	answer, err := h.answerService.GetAnswerByID(uint(id))
+	answer, err := h.answerService.UpdateAnswer(r.Context(), auth.FromContext(r.Context()), uint(id), &req, ifMatch(r))
 	if err != nil {
-		h.logger.Printf("Error getting answer: %v", err)
-		http.Error(w, "Answer not found", http.StatusNotFound)
+		logError(r, h.logger, "error updating answer", err)
+		problem.Write(w, r, err)
 		return
 	}
 
+	setValidators(w, answer.Version, answerLastModified(answer))
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(answer); err != nil {
-		h.logger.Printf("Error encoding answer: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "error encoding answer", "error", err)
+		return
+	}
+}
+
+func (h *AnswerHandler) GetAnswerRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	revisions, err := h.answerService.ListAnswerRevisions(r.Context(), uint(id))
+	if err != nil {
+		logError(r, h.logger, "error getting revisions", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding revisions", "error", err)
+		return
+	}
+}
+
+func (h *AnswerHandler) DiffAnswerRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	from, err := parseIntParam(r.URL.Query(), "from")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	to, err := parseIntParam(r.URL.Query(), "to")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	result, err := h.answerService.DiffAnswerRevisions(r.Context(), uint(id), from, to)
+	if err != nil {
+		logError(r, h.logger, "error diffing revisions", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding diff", "error", err)
+		return
+	}
+}
+
+func (h *AnswerHandler) RollbackAnswer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid version", "error", err)
+		problem.Write(w, r, errInvalidVersion)
+		return
+	}
+
+	answer, err := h.answerService.RollbackAnswer(r.Context(), auth.FromContext(r.Context()), uint(id), version, ifMatch(r))
+	if err != nil {
+		logError(r, h.logger, "error rolling back answer", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	setValidators(w, answer.Version, answerLastModified(answer))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(answer); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding answer", "error", err)
 		return
 	}
 }
@@ -103,27 +243,21 @@ func (h *AnswerHandler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "ID not provided", http.StatusBadRequest)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		h.logger.Printf("Invalid ID: %v", err)
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	h.logger.Printf("Handling DELETE /answers/%d", id)
-
-	err = h.answerService.DeleteAnswer(uint(id))
+	err = h.answerService.DeleteAnswer(r.Context(), auth.FromContext(r.Context()), uint(id), ifMatch(r))
 	if err != nil {
-		h.logger.Printf("Error deleting answer: %v", err)
-		if err.Error() == "answer not found" {
-			http.Error(w, "Answer not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		logError(r, h.logger, "error deleting answer", err)
+		problem.Write(w, r, err)
 		return
 	}
 
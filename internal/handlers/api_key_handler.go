@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+	"qa-service/internal/validation"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	logger        *slog.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(r.Context(), &req)
+	if err != nil {
+		logError(r, h.logger, "error creating API key", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding API key", "error", err)
+		return
+	}
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), uint(id)); err != nil {
+		logError(r, h.logger, "error revoking API key", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
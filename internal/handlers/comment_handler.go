@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/auth"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+	"qa-service/internal/validation"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type CommentHandler struct {
+	commentService *services.CommentService
+	logger         *slog.Logger
+}
+
+func NewCommentHandler(commentService *services.CommentService, logger *slog.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+func (h *CommentHandler) GetQuestionComments(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.CommentTargetQuestion)
+}
+
+func (h *CommentHandler) CreateQuestionComment(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, models.CommentTargetQuestion)
+}
+
+func (h *CommentHandler) DeleteQuestionComment(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, models.CommentTargetQuestion)
+}
+
+func (h *CommentHandler) GetAnswerComments(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.CommentTargetAnswer)
+}
+
+func (h *CommentHandler) CreateAnswerComment(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, models.CommentTargetAnswer)
+}
+
+func (h *CommentHandler) DeleteAnswerComment(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, models.CommentTargetAnswer)
+}
+
+func (h *CommentHandler) list(w http.ResponseWriter, r *http.Request, targetType string) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	comments, err := h.commentService.ListComments(r.Context(), targetType, uint(id))
+	if err != nil {
+		logError(r, h.logger, "error getting comments", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comments); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding comments", "error", err)
+		return
+	}
+}
+
+func (h *CommentHandler) create(w http.ResponseWriter, r *http.Request, targetType string) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(r.Context(), auth.FromContext(r.Context()), targetType, uint(id), &req)
+	if err != nil {
+		logError(r, h.logger, "error creating comment", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding comment", "error", err)
+		return
+	}
+}
+
+func (h *CommentHandler) delete(w http.ResponseWriter, r *http.Request, targetType string) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+	commentID, err := strconv.ParseUint(vars["commentID"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid comment ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	err = h.commentService.DeleteComment(r.Context(), auth.FromContext(r.Context()), targetType, uint(id), uint(commentID))
+	if err != nil {
+		logError(r, h.logger, "error deleting comment", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/models"
+	"qa-service/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setValidators sets a strong ETag and Last-Modified for an entity at
+// version, last changed at lastModified, and returns the ETag. The tag names
+// the version, which If-Match is compared on, and the time of the last
+// change to anything in the representation, such as votes and answers,
+// which If-None-Match is compared on.
+func setValidators(w http.ResponseWriter, version int, lastModified time.Time) string {
+	etag := fmt.Sprintf(`"%d-%d"`, version, lastModified.UnixMicro())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	return etag
+}
+
+// writeRepresentation writes v as JSON along with its validators. A GET for
+// a representation the client already has is answered with 304.
+func writeRepresentation(w http.ResponseWriter, r *http.Request, logger *slog.Logger, v interface{}, version int, lastModified time.Time) {
+	etag := setValidators(w, version, lastModified)
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.ErrorContext(r.Context(), "error encoding response", "error", err)
+		return
+	}
+}
+
+// notModified evaluates If-None-Match and, only when that is absent,
+// If-Modified-Since, whose dates have whole seconds.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if values := r.Header.Values("If-None-Match"); len(values) > 0 {
+		return etagListMatches(values, etag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether a list of entity tags, or "*", matches
+// etag by weak comparison.
+func etagListMatches(values []string, etag string) bool {
+	for _, value := range values {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ifMatch reads If-Match into the versions its tags name. Weak and malformed
+// tags name none, so they never match. It returns nil without the header.
+func ifMatch(r *http.Request) *services.Precondition {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return nil
+	}
+	cond := &services.Precondition{}
+	for _, value := range values {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" {
+				cond.Any = true
+				continue
+			}
+			if len(candidate) < 2 || candidate[0] != '"' || candidate[len(candidate)-1] != '"' {
+				continue
+			}
+			versionPart, _, ok := strings.Cut(candidate[1:len(candidate)-1], "-")
+			if !ok {
+				continue
+			}
+			if version, err := strconv.Atoi(versionPart); err == nil {
+				cond.Versions = append(cond.Versions, version)
+			}
+		}
+	}
+	return cond
+}
+
+// answerLastModified is when anything in an answer's representation last
+// changed, including the question embedded in it.
+func answerLastModified(answer *models.Answer) time.Time {
+	if answer.Question.UpdatedAt.After(answer.UpdatedAt) {
+		return answer.Question.UpdatedAt
+	}
+	return answer.UpdatedAt
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/health"
+)
+
+type HealthHandler struct {
+	probe  *health.Probe
+	logger *slog.Logger
+}
+
+func NewHealthHandler(probe *health.Probe, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		probe:  probe,
+		logger: logger,
+	}
+}
+
+// Livez reports that the process is up. It checks no dependencies, so an
+// outage of the database never gets the service restarted.
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.probe.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+		h.logger.WarnContext(r.Context(), "not ready", "status", report.Status, "checks", report.Checks)
+	}
+	h.write(w, r, status, report)
+}
+
+func (h *HealthHandler) write(w http.ResponseWriter, r *http.Request, status int, report health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding health report", "error", err)
+	}
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"qa-service/internal/problem"
+)
+
+// logError records a failed service call. Client errors are expected, so
+// they are only logged at debug level; the access log has their status.
+func logError(r *http.Request, logger *slog.Logger, msg string, err error) {
+	level := slog.LevelDebug
+	if problem.FromError(err).Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.Log(r.Context(), level, msg, "error", err)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+	"qa-service/internal/pagination"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidID      = apperr.Validation("invalid_id", "invalid ID")
+	errInvalidVersion = apperr.Validation("invalid_version", "invalid version")
+)
+
+func invalidParameter(format string, args ...interface{}) error {
+	return apperr.Validation("invalid_parameter", fmt.Sprintf(format, args...))
+}
+
+func parseIntParam(query url.Values, name string) (int, error) {
+	value, err := strconv.Atoi(query.Get(name))
+	if err != nil {
+		return 0, invalidParameter("%s must be an integer", name)
+	}
+	return value, nil
+}
+
+func parseLimit(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, invalidParameter("%s must be a positive integer", name)
+	}
+	return limit, nil
+}
+
+func parseOffset(query url.Values) (int, error) {
+	value := query.Get("offset")
+	if value == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, invalidParameter("offset must be a non-negative integer")
+	}
+	return offset, nil
+}
+
+func parseCursor(query url.Values, sort, order string) (*pagination.Cursor, error) {
+	value := query.Get("cursor")
+	if value == "" {
+		return nil, nil
+	}
+	cursor, err := pagination.Decode(value)
+	if err != nil {
+		return nil, apperr.Validation("invalid_cursor", err.Error())
+	}
+	if cursor.Sort != sort || cursor.Order != order {
+		return nil, apperr.Validation("invalid_cursor", "cursor does not match sort parameters")
+	}
+	return cursor, nil
+}
+
+func parseEnum(query url.Values, name, defaultValue string, allowed ...string) (string, error) {
+	value := query.Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value, nil
+		}
+	}
+	return "", invalidParameter("invalid %s: %q", name, value)
+}
+
+func parseAnswerListOptions(query url.Values, prefix string) (models.AnswerListOptions, error) {
+	var opts models.AnswerListOptions
+	var err error
+
+	if opts.Limit, err = parseLimit(query, prefix+"limit"); err != nil {
+		return opts, err
+	}
+	if opts.Order, err = parseEnum(query, prefix+"order", models.AnswerOrderOldest, models.AnswerOrderOldest, models.AnswerOrderNewest, models.AnswerOrderUser, models.AnswerOrderScore); err != nil {
+		return opts, err
+	}
+	if prefix == "" {
+		if opts.Cursor, err = parseCursor(query, opts.Order, ""); err != nil {
+			return opts, err
+		}
+		opts.UserID = query.Get("user_id")
+	}
+	return opts, nil
+}
+
+func parseTime(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, invalidParameter("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+func parseBool(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalidParameter("%s must be a boolean", name)
+	}
+	return b, nil
+}
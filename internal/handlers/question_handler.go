@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"qa-service/internal/auth"
 	"qa-service/internal/models"
+	"qa-service/internal/problem"
 	"qa-service/internal/services"
+	"qa-service/internal/validation"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -13,10 +17,10 @@ import (
 
 type QuestionHandler struct {
 	questionService *services.QuestionService
-	logger          *log.Logger
+	logger          *slog.Logger
 }
 
-func NewQuestionHandler(questionService *services.QuestionService, logger *log.Logger) *QuestionHandler {
+func NewQuestionHandler(questionService *services.QuestionService, logger *slog.Logger) *QuestionHandler {
 	return &QuestionHandler{
 		questionService: questionService,
 		logger:          logger,
@@ -24,45 +28,78 @@ func NewQuestionHandler(questionService *services.QuestionService, logger *log.L
 }
 
 func (h *QuestionHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
-	h.logger.Println("Handling GET /questions/")
+	opts, err := parseQuestionListOptions(r.URL.Query())
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid list parameters", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
 
-	questions, err := h.questionService.GetAllQuestions()
+	page, err := h.questionService.ListQuestions(r.Context(), opts)
 	if err != nil {
-		h.logger.Printf("Error getting questions: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logError(r, h.logger, "error getting questions", err)
+		problem.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(questions); err != nil {
-		h.logger.Printf("Error encoding questions: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding questions", "error", err)
 		return
 	}
 }
 
-func (h *QuestionHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
-	h.logger.Println("Handling POST /questions/")
+func parseQuestionListOptions(query url.Values) (models.QuestionListOptions, error) {
+	var opts models.QuestionListOptions
+	var err error
 
+	if opts.Limit, err = parseLimit(query, "limit"); err != nil {
+		return opts, err
+	}
+	if opts.Sort, err = parseEnum(query, "sort", models.QuestionSortCreatedAt, models.QuestionSortCreatedAt, models.QuestionSortAnswers); err != nil {
+		return opts, err
+	}
+	if opts.Order, err = parseEnum(query, "order", models.SortDesc, models.SortAsc, models.SortDesc); err != nil {
+		return opts, err
+	}
+	if opts.Cursor, err = parseCursor(query, opts.Sort, opts.Order); err != nil {
+		return opts, err
+	}
+	if opts.CreatedAfter, err = parseTime(query, "created_after"); err != nil {
+		return opts, err
+	}
+	if opts.CreatedBefore, err = parseTime(query, "created_before"); err != nil {
+		return opts, err
+	}
+	if opts.Unanswered, err = parseBool(query, "unanswered"); err != nil {
+		return opts, err
+	}
+	if opts.TagMode, err = parseEnum(query, "tag_mode", models.TagModeAll, models.TagModeAll, models.TagModeAny); err != nil {
+		return opts, err
+	}
+	opts.Tags = query["tag"]
+	return opts, nil
+}
+
+func (h *QuestionHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateQuestionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Printf("Error decoding request: %v", err)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
 		return
 	}
 
-	question, err := h.questionService.CreateQuestion(&req)
+	question, err := h.questionService.CreateQuestion(r.Context(), auth.FromContext(r.Context()), &req)
 	if err != nil {
-		h.logger.Printf("Error creating question: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logError(r, h.logger, "error creating question", err)
+		problem.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(question); err != nil {
-		h.logger.Printf("Error encoding question: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "error encoding question", "error", err)
 		return
 	}
 }
@@ -71,30 +108,198 @@ func (h *QuestionHandler) GetQuestion(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "ID not provided", http.StatusBadRequest)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		h.logger.Printf("Invalid ID: %v", err)
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	answerOpts, err := parseAnswerListOptions(r.URL.Query(), "answers_")
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid answer parameters", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	question, err := h.questionService.GetQuestionByID(r.Context(), uint(id), answerOpts)
+	if err != nil {
+		logError(r, h.logger, "error getting question", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	writeRepresentation(w, r, h.logger, question, question.Version, question.UpdatedAt)
+}
+
+func (h *QuestionHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var req models.UpdateQuestionRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	question, err := h.questionService.UpdateQuestion(r.Context(), auth.FromContext(r.Context()), uint(id), &req, ifMatch(r))
+	if err != nil {
+		logError(r, h.logger, "error updating question", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	setValidators(w, question.Version, question.UpdatedAt)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(question); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding question", "error", err)
+		return
+	}
+}
+
+func (h *QuestionHandler) GetQuestionRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	revisions, err := h.questionService.ListQuestionRevisions(r.Context(), uint(id))
+	if err != nil {
+		logError(r, h.logger, "error getting revisions", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding revisions", "error", err)
+		return
+	}
+}
+
+func (h *QuestionHandler) DiffQuestionRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	h.logger.Printf("Handling GET /questions/%d", id)
+	from, err := parseIntParam(r.URL.Query(), "from")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	to, err := parseIntParam(r.URL.Query(), "to")
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
 
-	question, err := h.questionService.GetQuestionByID(uint(id))
+	result, err := h.questionService.DiffQuestionRevisions(r.Context(), uint(id), from, to)
 	if err != nil {
-		h.logger.Printf("Error getting question: %v", err)
-		http.Error(w, "Question not found", http.StatusNotFound)
+		logError(r, h.logger, "error diffing revisions", err)
+		problem.Write(w, r, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding diff", "error", err)
+		return
+	}
+}
+
+func (h *QuestionHandler) RollbackQuestion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid version", "error", err)
+		problem.Write(w, r, errInvalidVersion)
+		return
+	}
+
+	question, err := h.questionService.RollbackQuestion(r.Context(), auth.FromContext(r.Context()), uint(id), version, ifMatch(r))
+	if err != nil {
+		logError(r, h.logger, "error rolling back question", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	setValidators(w, question.Version, question.UpdatedAt)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(question); err != nil {
-		h.logger.Printf("Error encoding question: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "error encoding question", "error", err)
+		return
+	}
+}
+
+func (h *QuestionHandler) AcceptAnswer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var req models.AcceptAnswerRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	question, err := h.questionService.AcceptAnswer(r.Context(), auth.FromContext(r.Context()), uint(id), &req, ifMatch(r))
+	if err != nil {
+		logError(r, h.logger, "error accepting answer", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	setValidators(w, question.Version, question.UpdatedAt)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(question); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding question", "error", err)
+		return
+	}
+}
+
+func (h *QuestionHandler) UnacceptAnswer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	question, err := h.questionService.UnacceptAnswer(r.Context(), auth.FromContext(r.Context()), uint(id), ifMatch(r))
+	if err != nil {
+		logError(r, h.logger, "error unaccepting answer", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	setValidators(w, question.Version, question.UpdatedAt)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(question); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding question", "error", err)
 		return
 	}
 }
@@ -103,27 +308,21 @@ func (h *QuestionHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "ID not provided", http.StatusBadRequest)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		h.logger.Printf("Invalid ID: %v", err)
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	h.logger.Printf("Handling DELETE /questions/%d", id)
-
-	err = h.questionService.DeleteQuestion(uint(id))
+	err = h.questionService.DeleteQuestion(r.Context(), auth.FromContext(r.Context()), uint(id), ifMatch(r))
 	if err != nil {
-		h.logger.Printf("Error deleting question: %v", err)
-		if err.Error() == "question not found" {
-			http.Error(w, "Question not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		logError(r, h.logger, "error deleting question", err)
+		problem.Write(w, r, err)
 		return
 	}
 
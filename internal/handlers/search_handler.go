@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+)
+
+type SearchHandler struct {
+	searchService *services.SearchService
+	logger        *slog.Logger
+}
+
+func NewSearchHandler(searchService *services.SearchService, logger *slog.Logger) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := models.SearchOptions{Query: query.Get("q")}
+	var err error
+
+	if opts.Limit, err = parseLimit(query, "limit"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if opts.Offset, err = parseOffset(query); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if opts.Language, err = parseEnum(query, "lang", "", models.SearchLanguageRussian, models.SearchLanguageEnglish); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if opts.Type, err = parseEnum(query, "type", "", models.SearchTypeQuestion, models.SearchTypeAnswer); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	results, err := h.searchService.Search(r.Context(), opts)
+	if err != nil {
+		logError(r, h.logger, "error searching", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding search results", "error", err)
+		return
+	}
+}
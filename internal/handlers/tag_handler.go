@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+	"qa-service/internal/validation"
+
+	"github.com/gorilla/mux"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+	logger     *slog.Logger
+}
+
+func NewTagHandler(tagService *services.TagService, logger *slog.Logger) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		logger:     logger,
+	}
+}
+
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.tagService.ListTags(r.Context())
+	if err != nil {
+		logError(r, h.logger, "error getting tags", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tags); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding tags", "error", err)
+		return
+	}
+}
+
+func (h *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	var req models.RenameTagRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := h.tagService.RenameTag(r.Context(), name, &req); err != nil {
+		logError(r, h.logger, "error renaming tag", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	var req models.MergeTagRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := h.tagService.MergeTag(r.Context(), name, &req); err != nil {
+		logError(r, h.logger, "error merging tag", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TrashHandler struct {
+	trashService *services.TrashService
+	logger       *slog.Logger
+}
+
+func NewTrashHandler(trashService *services.TrashService, logger *slog.Logger) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+		logger:       logger,
+	}
+}
+
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var opts models.TrashOptions
+	var err error
+
+	if opts.Limit, err = parseLimit(query, "limit"); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	if opts.Offset, err = parseOffset(query); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	items, err := h.trashService.ListTrash(r.Context(), opts)
+	if err != nil {
+		logError(r, h.logger, "error listing trash", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding trash", "error", err)
+		return
+	}
+}
+
+func (h *TrashHandler) RestoreQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.trashService.RestoreQuestion(r.Context(), uint(id)); err != nil {
+		logError(r, h.logger, "error restoring question", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) RestoreAnswer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.trashService.RestoreAnswer(r.Context(), uint(id)); err != nil {
+		logError(r, h.logger, "error restoring answer", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
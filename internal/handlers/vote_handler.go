@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/auth"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/services"
+	"qa-service/internal/validation"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type VoteHandler struct {
+	voteService *services.VoteService
+	logger      *slog.Logger
+}
+
+func NewVoteHandler(voteService *services.VoteService, logger *slog.Logger) *VoteHandler {
+	return &VoteHandler{
+		voteService: voteService,
+		logger:      logger,
+	}
+}
+
+func (h *VoteHandler) VoteQuestion(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, models.VoteTargetQuestion)
+}
+
+func (h *VoteHandler) VoteAnswer(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, models.VoteTargetAnswer)
+}
+
+func (h *VoteHandler) vote(w http.ResponseWriter, r *http.Request, targetType string) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid ID", "error", err)
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var req models.VoteRequest
+	if err := validation.Decode(w, r, &req); err != nil {
+		h.logger.DebugContext(r.Context(), "invalid request", "error", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	result, err := h.voteService.Vote(r.Context(), auth.FromContext(r.Context()), targetType, uint(id), &req)
+	if err != nil {
+		logError(r, h.logger, "error voting", err)
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding vote", "error", err)
+		return
+	}
+}
@@ -0,0 +1,95 @@
+// Package health runs the dependency checks behind the readiness probe.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailed   = "failed"
+	StatusDraining = "draining"
+)
+
+// Check verifies one dependency. It must return once ctx is done.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Probe decides whether the service can take traffic.
+type Probe struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewProbe returns a probe running checks with a per-check timeout.
+func NewProbe(timeout time.Duration, checks ...Check) *Probe {
+	return &Probe{checks: checks, timeout: timeout}
+}
+
+// Drain marks the service as not ready for good, so load balancers stop
+// routing to it before it shuts down.
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Ready runs all checks concurrently. The report status is StatusOK only if
+// every check passed and the service is not draining.
+func (p *Probe) Ready(ctx context.Context) Report {
+	if p.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(p.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range p.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := p.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFailed
+			}
+		}(check)
+	}
+	wg.Wait()
+	return report
+}
+
+func (p *Probe) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+// Package idempotency remembers the responses to requests sent with an
+// idempotency key, so that a retried request is answered from the first
+// attempt instead of being carried out twice.
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInFlight means the first request with the key is still running.
+	ErrInFlight = errors.New("a request with this idempotency key is in progress")
+	// ErrMismatch means the key was used for a different request.
+	ErrMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// Response is a stored response, replayed to retries.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps the claimed keys and their responses.
+type Store interface {
+	// Begin claims key for a request whose content hashes to fingerprint
+	// and returns the claim token. When the key was used before for the
+	// same request it returns the stored response, ErrInFlight while that
+	// request is still running and ErrMismatch when it was used for a
+	// different request. A claim that is neither completed nor released
+	// lapses after lockTimeout.
+	Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (claim string, stored *Response, err error)
+	// Complete stores the response to a claimed key for ttl. It does nothing
+	// once the claim has lapsed and another request has taken the key.
+	Complete(ctx context.Context, key, claim string, resp Response, ttl time.Duration) error
+	// Release drops a claim, so that the request can be tried again. Like
+	// Complete it leaves a key claimed by another request alone.
+	Release(ctx context.Context, key, claim string) error
+}
+
+func newClaim() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
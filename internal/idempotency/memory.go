@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// MemoryStore keeps keys in the process, so retries are only recognized by
+// the replica that served the first request.
+type MemoryStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	fingerprint string
+	claim       string
+	// response is nil while the request is in flight.
+	response *Response
+	expires  time.Time
+}
+
+// NewMemoryStore returns an empty store reading the time from now, or from
+// time.Now when now is nil.
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryStore{now: now, entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (string, *Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		switch {
+		case e.fingerprint != fingerprint:
+			return "", nil, ErrMismatch
+		case e.response == nil:
+			return "", nil, ErrInFlight
+		}
+		return "", e.response, nil
+	}
+	claim, err := newClaim()
+	if err != nil {
+		return "", nil, err
+	}
+	s.entries[key] = memoryEntry{fingerprint: fingerprint, claim: claim, expires: now.Add(lockTimeout)}
+	return claim, nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key, claim string, resp Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.claim != claim || e.response != nil {
+		// The claim lapsed and was swept or taken over.
+		return nil
+	}
+	e.response = &resp
+	e.expires = s.now().Add(ttl)
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key, claim string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.claim == claim && e.response == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
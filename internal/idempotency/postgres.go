@@ -0,0 +1,103 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps keys in the idempotency_keys table, so a retry is
+// recognized by any replica sharing the database. Expiry is measured with
+// the database clock.
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (string, *Response, error) {
+	db := s.db.WithContext(ctx)
+	if s.sweepDue() {
+		if err := db.Exec("DELETE FROM idempotency_keys WHERE expires_at <= now()").Error; err != nil {
+			return "", nil, err
+		}
+	}
+
+	claim, err := newClaim()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for {
+		// Claim the key unless a live row holds it; an expired row is
+		// taken over in place.
+		claimed := db.Exec(`INSERT INTO idempotency_keys (key, fingerprint, claim, expires_at)
+			VALUES (?, ?, ?, now() + make_interval(secs => ?))
+			ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, claim = EXCLUDED.claim, status = NULL, headers = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()`, key, fingerprint, claim, lockTimeout.Seconds())
+		if claimed.Error != nil {
+			return "", nil, claimed.Error
+		}
+		if claimed.RowsAffected == 1 {
+			return claim, nil, nil
+		}
+
+		var row struct {
+			Fingerprint string
+			Status      *int
+			Headers     []byte
+			Body        []byte
+		}
+		found := db.Raw("SELECT fingerprint, status, headers, body FROM idempotency_keys WHERE key = ? AND expires_at > now()", key).Scan(&row)
+		if found.Error != nil {
+			return "", nil, found.Error
+		}
+		if found.RowsAffected == 0 {
+			// The row expired in between; claim it again.
+			continue
+		}
+
+		switch {
+		case row.Fingerprint != fingerprint:
+			return "", nil, ErrMismatch
+		case row.Status == nil:
+			return "", nil, ErrInFlight
+		}
+		resp := &Response{Status: *row.Status, Body: row.Body}
+		if err := json.Unmarshal(row.Headers, &resp.Header); err != nil {
+			return "", nil, err
+		}
+		return "", resp, nil
+	}
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key, claim string, resp Response, ttl time.Duration) error {
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(`UPDATE idempotency_keys SET status = ?, headers = ?, body = ?, expires_at = now() + make_interval(secs => ?)
+		WHERE key = ? AND claim = ? AND status IS NULL`, resp.Status, string(headers), resp.Body, ttl.Seconds(), key, claim).Error
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key, claim string) error {
+	return s.db.WithContext(ctx).Exec("DELETE FROM idempotency_keys WHERE key = ? AND claim = ? AND status IS NULL", key, claim).Error
+}
+
+func (s *PostgresStore) sweepDue() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		return false
+	}
+	s.lastSweep = time.Now()
+	return true
+}
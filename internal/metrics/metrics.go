@@ -0,0 +1,103 @@
+// Package metrics collects the service's Prometheus metrics: HTTP traffic,
+// database queries and connection pool usage, and business events.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns a registry, so each instance, such as one per test server,
+// starts from zero.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+
+	questionsCreated prometheus.Counter
+	questionsDeleted prometheus.Counter
+	answersCreated   prometheus.Counter
+	answersDeleted   prometheus.Counter
+
+	rateLimited *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query latency by GORM operation and table.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		questionsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "qa_questions_created_total",
+			Help: "Questions created.",
+		}),
+		questionsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "qa_questions_deleted_total",
+			Help: "Questions moved to the trash.",
+		}),
+		answersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "qa_answers_created_total",
+			Help: "Answers created.",
+		}),
+		answersDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "qa_answers_deleted_total",
+			Help: "Answers moved to the trash.",
+		}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "qa_rate_limited_requests_total",
+			Help: "Requests rejected by the rate limiter, by route group.",
+		}, []string{"group"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		m.questionsCreated,
+		m.questionsDeleted,
+		m.answersCreated,
+		m.answersDeleted,
+		m.rateLimited,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records a finished HTTP request. route is the path
+// template, never the raw path, to keep label cardinality bounded.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (m *Metrics) QuestionCreated() { m.questionsCreated.Inc() }
+func (m *Metrics) QuestionDeleted() { m.questionsDeleted.Inc() }
+func (m *Metrics) AnswerCreated()   { m.answersCreated.Inc() }
+func (m *Metrics) AnswerDeleted()   { m.answersDeleted.Inc() }
+
+func (m *Metrics) RateLimited(group string) { m.rateLimited.WithLabelValues(group).Inc() }
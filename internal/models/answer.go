@@ -2,18 +2,49 @@ package models
 
 import (
 	"time"
+
+	"qa-service/internal/pagination"
+
+	"gorm.io/gorm"
 )
 
 type Answer struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	QuestionID uint      `json:"question_id" gorm:"not null"`
-	UserID     string    `json:"user_id" gorm:"not null" validate:"required"`
-	Text       string    `json:"text" gorm:"not null" validate:"required,min=1,max=2000"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
-	Question   Question  `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+	ID         uint           `json:"id" gorm:"primaryKey;type:serial"`
+	QuestionID uint           `json:"question_id" gorm:"type:integer;not null"`
+	UserID     string         `json:"user_id" gorm:"type:varchar(255);not null" validate:"required"`
+	Text       string         `json:"text" gorm:"not null" validate:"required,min=1,max=2000"`
+	Score      int            `json:"score" gorm:"type:integer;not null;default:0"`
+	Version    int            `json:"version" gorm:"type:integer;not null;default:1"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	Question   Question       `json:"question,omitempty" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
 }
 
 type CreateAnswerRequest struct {
-	UserID string `json:"user_id" validate:"required"`
-	Text   string `json:"text" validate:"required,min=1,max=2000"`
+	Text string `json:"text" validate:"required,min=1,max=2000"`
+}
+
+type UpdateAnswerRequest struct {
+	Text string `json:"text" validate:"required,min=1,max=2000"`
+}
+
+const (
+	AnswerOrderOldest = "oldest"
+	AnswerOrderNewest = "newest"
+	AnswerOrderUser   = "user"
+	AnswerOrderScore  = "score"
+)
+
+type AnswerListOptions struct {
+	Limit  int
+	Cursor *pagination.Cursor
+	Order  string
+	UserID string
+}
+
+type AnswerPage struct {
+	Items      []Answer `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Total      int64    `json:"total"`
 }
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RoleList is stored as a comma separated column.
+type RoleList []string
+
+func (l RoleList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *RoleList) Scan(value any) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into RoleList", value)
+	}
+
+	*l = RoleList{}
+	for _, role := range strings.Split(s, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			*l = append(*l, role)
+		}
+	}
+	return nil
+}
+
+// APIKey is a long-lived credential. Only the SHA-256 hash of the key is kept.
+type APIKey struct {
+	ID        uint       `json:"id" gorm:"primaryKey;type:serial"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(255);not null;index"`
+	Name      string     `json:"name" gorm:"type:varchar(255);not null;default:''"`
+	KeyHash   string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Roles     RoleList   `json:"roles" gorm:"type:text;not null;default:'user'"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	UserID string   `json:"user_id" validate:"required"`
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+}
+
+// CreatedAPIKey is returned once, when the key is issued; the plain key cannot
+// be recovered afterwards.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	CommentTargetQuestion = "question"
+	CommentTargetAnswer   = "answer"
+)
+
+// CommentMaxLength is the longest comment, in characters.
+const CommentMaxLength = 600
+
+type Comment struct {
+	ID         uint      `json:"id" gorm:"primaryKey;type:serial"`
+	TargetType string    `json:"target_type" gorm:"type:varchar(16);not null"`
+	TargetID   uint      `json:"target_id" gorm:"type:integer;not null"`
+	UserID     string    `json:"user_id" gorm:"type:varchar(255);not null"`
+	Text       string    `json:"text" gorm:"type:varchar(600);not null" validate:"required,min=1,max=600"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateCommentRequest struct {
+	Text string `json:"text" validate:"required,min=1,max=600"`
+}
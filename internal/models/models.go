@@ -0,0 +1,7 @@
+package models
+
+// All returns a value of every model stored in its own table, which is what
+// the schema check compares against the database.
+func All() []interface{} {
+	return []interface{}{&Question{}, &Answer{}, &Revision{}, &Vote{}, &Tag{}, &APIKey{}, &Comment{}}
+}
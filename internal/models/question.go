@@ -2,15 +2,70 @@ package models
 
 import (
 	"time"
+
+	"qa-service/internal/pagination"
+
+	"gorm.io/gorm"
 )
 
 type Question struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Text      string    `json:"text" gorm:"not null" validate:"required,min=1,max=1000"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	Answers   []Answer  `json:"answers,omitempty" gorm:"foreignKey:QuestionID"`
+	ID               uint           `json:"id" gorm:"primaryKey;type:serial"`
+	UserID           string         `json:"user_id" gorm:"type:varchar(255);not null;default:''"`
+	Text             string         `json:"text" gorm:"not null" validate:"required,min=1,max=1000"`
+	AnswerCount      int            `json:"answer_count" gorm:"type:integer;not null;default:0"`
+	Score            int            `json:"score" gorm:"type:integer;not null;default:0"`
+	AcceptedAnswerID *uint          `json:"accepted_answer_id" gorm:"type:integer"`
+	Version          int            `json:"version" gorm:"type:integer;not null;default:1"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+	Tags             []Tag          `json:"tags" gorm:"many2many:question_tags;constraint:OnDelete:CASCADE"`
+	Answers          []Answer       `json:"answers,omitempty" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+
+	AnswersNextCursor string `json:"answers_next_cursor,omitempty" gorm:"-"`
 }
 
 type CreateQuestionRequest struct {
-	Text string `json:"text" validate:"required,min=1,max=1000"`
+	Text string   `json:"text" validate:"required,min=1,max=1000"`
+	Tags []string `json:"tags" validate:"max=5"`
+}
+
+// UpdateQuestionRequest changes only the fields that are present.
+type UpdateQuestionRequest struct {
+	Text *string   `json:"text" validate:"omitempty,min=1,max=1000"`
+	Tags *[]string `json:"tags" validate:"omitempty,max=5"`
+}
+
+type AcceptAnswerRequest struct {
+	AnswerID uint `json:"answer_id" validate:"required"`
+}
+
+const (
+	QuestionSortCreatedAt = "created_at"
+	QuestionSortAnswers   = "answers"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+type QuestionListOptions struct {
+	Limit         int
+	Cursor        *pagination.Cursor
+	Sort          string
+	Order         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Unanswered    bool
+	Tags          []string
+	TagMode       string
+}
+
+func (o QuestionListOptions) Filtered() bool {
+	return o.CreatedAfter != nil || o.CreatedBefore != nil || o.Unanswered || len(o.Tags) > 0
+}
+
+type QuestionPage struct {
+	Items         []Question `json:"items"`
+	NextCursor    string     `json:"next_cursor,omitempty"`
+	TotalEstimate int64      `json:"total_estimate"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"qa-service/internal/diff"
+)
+
+const (
+	RevisionEntityQuestion = "question"
+	RevisionEntityAnswer   = "answer"
+)
+
+// Revision keeps the text an entity had at Version, together with the editor
+// who replaced it and when that happened.
+type Revision struct {
+	ID         uint      `json:"id" gorm:"primaryKey;type:serial"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(16);not null"`
+	EntityID   uint      `json:"entity_id" gorm:"type:integer;not null"`
+	Version    int       `json:"version" gorm:"type:integer;not null"`
+	EditorID   string    `json:"editor_id" gorm:"type:varchar(255);not null"`
+	Text       string    `json:"text" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type RevisionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []diff.Change `json:"changes"`
+}
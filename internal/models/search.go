@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	SearchTypeQuestion = "question"
+	SearchTypeAnswer   = "answer"
+
+	SearchLanguageRussian = "ru"
+	SearchLanguageEnglish = "en"
+)
+
+type SearchOptions struct {
+	Query    string
+	Language string
+	Type     string
+	Limit    int
+	Offset   int
+}
+
+type SearchResult struct {
+	Type       string    `json:"type"`
+	ID         uint      `json:"id"`
+	QuestionID uint      `json:"question_id"`
+	Snippet    string    `json:"snippet"`
+	Rank       float64   `json:"rank"`
+	CreatedAt  time.Time `json:"created_at"`
+}
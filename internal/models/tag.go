@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	TagModeAll = "all"
+	TagModeAny = "any"
+)
+
+type Tag struct {
+	ID        uint      `json:"-" gorm:"primaryKey;type:serial"`
+	Name      string    `json:"name" gorm:"type:varchar(35);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"-" gorm:"autoCreateTime"`
+}
+
+type TagUsage struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type MergeTagRequest struct {
+	Into string `json:"into" validate:"required"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	TrashTypeQuestion = "question"
+	TrashTypeAnswer   = "answer"
+)
+
+// TrashItem is a soft-deleted question, or an answer that was deleted on its
+// own while its question is still live.
+type TrashItem struct {
+	Type       string    `json:"type"`
+	ID         uint      `json:"id"`
+	QuestionID uint      `json:"question_id"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+type TrashOptions struct {
+	Limit  int
+	Offset int
+}
+
+type PurgeResult struct {
+	Questions int64 `json:"questions"`
+	Answers   int64 `json:"answers"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	VoteTargetQuestion = "question"
+	VoteTargetAnswer   = "answer"
+)
+
+type Vote struct {
+	ID         uint      `json:"id" gorm:"primaryKey;type:serial"`
+	UserID     string    `json:"user_id" gorm:"type:varchar(255);not null"`
+	TargetType string    `json:"target_type" gorm:"type:varchar(16);not null"`
+	TargetID   uint      `json:"target_id" gorm:"type:integer;not null"`
+	Value      int       `json:"value" gorm:"type:smallint;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// VoteRequest casts, changes or, with a zero value, withdraws a vote.
+type VoteRequest struct {
+	Value int `json:"value" validate:"oneof=-1 0 1"`
+}
+
+type VoteResult struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Score      int    `json:"score"`
+	UserVote   int    `json:"user_vote"`
+}
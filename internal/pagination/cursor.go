@@ -0,0 +1,44 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Cursor points at the last item of a page for keyset pagination. Only the
+// field matching Sort is meaningful; ID breaks ties between equal keys.
+type Cursor struct {
+	Sort   string    `json:"s"`
+	Order  string    `json:"o"`
+	Time   time.Time `json:"t,omitempty"`
+	Number int64     `json:"n,omitempty"`
+	Text   string    `json:"x,omitempty"`
+	ID     uint      `json:"id"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func Encode(c Cursor) string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func Decode(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
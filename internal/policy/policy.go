@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"slices"
+	"strings"
+)
+
+const (
+	ActionEditQuestion   = "question.edit"
+	ActionDeleteQuestion = "question.delete"
+	ActionAcceptAnswer   = "question.accept"
+	ActionEditAnswer     = "answer.edit"
+	ActionDeleteAnswer   = "answer.delete"
+	ActionDeleteComment  = "comment.delete"
+	ActionAdmin          = "admin"
+)
+
+// ConditionNoAnswers holds for a question that has not been answered yet.
+const ConditionNoAnswers = "no_answers"
+
+var actionDescriptions = map[string]string{
+	ActionEditQuestion:   "edit this question",
+	ActionDeleteQuestion: "delete this question",
+	ActionAcceptAnswer:   "accept an answer to this question",
+	ActionEditAnswer:     "edit this answer",
+	ActionDeleteAnswer:   "delete this answer",
+	ActionDeleteComment:  "delete this comment",
+	ActionAdmin:          "use administrative endpoints",
+}
+
+var conditionDescriptions = map[string]string{
+	ConditionNoAnswers: "it has no answers",
+}
+
+// Rule grants an action to principals that satisfy every constraint it sets.
+// An empty rule matches any authenticated principal.
+type Rule struct {
+	Roles      []string `json:"roles,omitempty"`
+	Owner      bool     `json:"owner,omitempty"`
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+// Config maps each action to the rules that allow it; an action is allowed
+// when at least one of its rules matches.
+type Config map[string][]Rule
+
+// Resource describes the object an action is applied to.
+type Resource struct {
+	OwnerID     string
+	AnswerCount int
+}
+
+type DeniedError struct {
+	Action string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return e.Reason
+}
+
+func (e *DeniedError) Is(target error) bool {
+	return target == apperr.ErrForbidden
+}
+
+func IsDenied(err error) bool {
+	var denied *DeniedError
+	return errors.As(err, &denied)
+}
+
+type Policy struct {
+	rules Config
+}
+
+func DefaultConfig() Config {
+	staff := []string{auth.RoleModerator, auth.RoleAdmin}
+	return Config{
+		ActionEditQuestion:   {{Roles: staff}, {Owner: true}},
+		ActionDeleteQuestion: {{Roles: staff}, {Owner: true, Conditions: []string{ConditionNoAnswers}}},
+		ActionAcceptAnswer:   {{Owner: true}},
+		ActionEditAnswer:     {{Roles: staff}, {Owner: true}},
+		ActionDeleteAnswer:   {{Roles: staff}, {Owner: true}},
+		ActionDeleteComment:  {{Roles: staff}, {Owner: true}},
+		ActionAdmin:          {{Roles: []string{auth.RoleAdmin}}},
+	}
+}
+
+func New(config Config) (*Policy, error) {
+	for action, rules := range config {
+		if _, ok := actionDescriptions[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		for _, rule := range rules {
+			for _, condition := range rule.Conditions {
+				if _, ok := conditionDescriptions[condition]; !ok {
+					return nil, fmt.Errorf("action %q: unknown condition %q", action, condition)
+				}
+			}
+		}
+	}
+
+	rules := DefaultConfig()
+	for action, actionRules := range config {
+		rules[action] = actionRules
+	}
+	return &Policy{rules: rules}, nil
+}
+
+func Default() *Policy {
+	return &Policy{rules: DefaultConfig()}
+}
+
+// Load reads a JSON policy file. Actions the file does not mention keep
+// their default rules.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	p, err := New(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Authorize returns a *DeniedError when principal may not perform action on
+// resource.
+func (p *Policy) Authorize(principal *auth.Principal, action string, resource Resource) error {
+	rules := p.rules[action]
+	if principal != nil {
+		for _, rule := range rules {
+			if rule.matches(principal, resource) {
+				return nil
+			}
+		}
+	}
+
+	return &DeniedError{Action: action, Reason: reason(action, rules)}
+}
+
+func (r Rule) matches(principal *auth.Principal, resource Resource) bool {
+	if len(r.Roles) > 0 && !slices.ContainsFunc(r.Roles, principal.HasRole) {
+		return false
+	}
+	if r.Owner && (resource.OwnerID == "" || resource.OwnerID != principal.Subject) {
+		return false
+	}
+	for _, condition := range r.Conditions {
+		if condition == ConditionNoAnswers && resource.AnswerCount > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rule) describe() string {
+	var parts []string
+	if len(r.Roles) > 0 {
+		parts = append(parts, "the "+strings.Join(r.Roles, " or ")+" role")
+	}
+	if r.Owner {
+		parts = append(parts, "being the author")
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "being signed in")
+	}
+
+	description := strings.Join(parts, " and ")
+	var conditions []string
+	for _, condition := range r.Conditions {
+		conditions = append(conditions, conditionDescriptions[condition])
+	}
+	if len(conditions) > 0 {
+		description += " while " + strings.Join(conditions, " and ")
+	}
+	return description
+}
+
+func reason(action string, rules []Rule) string {
+	description := "not allowed to " + actionDescriptions[action]
+	if len(rules) == 0 {
+		return description
+	}
+
+	requirements := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		requirements = append(requirements, rule.describe())
+	}
+	return description + ": requires " + strings.Join(requirements, ", or ")
+}
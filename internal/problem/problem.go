@@ -0,0 +1,81 @@
+// Package problem writes errors as RFC 7807 application/problem+json
+// responses.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/apperr"
+)
+
+const ContentType = "application/problem+json"
+
+// Details is an RFC 7807 problem object. Code is a stable, machine-readable
+// identifier of the error; Detail is meant for humans and may change.
+type Details struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Code     string              `json:"code"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Errors   []apperr.FieldError `json:"errors,omitempty"`
+}
+
+var kinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{apperr.ErrNotFound, http.StatusNotFound, "not_found"},
+	{apperr.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{apperr.ErrConflict, http.StatusConflict, "conflict"},
+	{apperr.ErrUnprocessable, http.StatusUnprocessableEntity, "unprocessable"},
+	{apperr.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{apperr.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{apperr.ErrUnavailable, http.StatusServiceUnavailable, "unavailable"},
+	{apperr.ErrTimeout, http.StatusGatewayTimeout, "timeout"},
+	{apperr.ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{apperr.ErrPrecondition, http.StatusPreconditionFailed, "precondition_failed"},
+}
+
+// FromError builds the problem for err. Errors of an unknown kind become a
+// generic 500 so that internal details never reach the client.
+func FromError(err error) Details {
+	for _, k := range kinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+		details := Details{Type: "about:blank", Title: http.StatusText(k.status), Status: k.status, Code: k.code, Detail: err.Error()}
+		var appErr *apperr.Error
+		if errors.As(err, &appErr) {
+			details.Code = appErr.Code
+			details.Detail = appErr.Message
+			details.Errors = appErr.Fields
+		}
+		return details
+	}
+
+	return Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Code:   "internal_error",
+		Detail: "internal server error",
+	}
+}
+
+// Write sends err to the client as a problem response.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	details := FromError(err)
+	details.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(details.Status)
+	if err := json.NewEncoder(w).Encode(details); err != nil {
+		slog.ErrorContext(r.Context(), "error writing problem response", "error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in the process, so each replica limits on its
+// own.
+type MemoryStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	// full is when the bucket will have refilled completely. From then on
+	// it is no different from a missing one and can be dropped.
+	full time.Time
+}
+
+// NewMemoryStore returns an empty store reading the time from now, or from
+// time.Now when now is nil.
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryStore{now: now, buckets: make(map[string]memoryBucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, p Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b.bucket = newBucket(p, now)
+	}
+	var result Result
+	b.bucket, result = take(b.bucket, p, now)
+	b.full = now.Add(result.Reset)
+	s.buckets[key] = b
+	return result, nil
+}
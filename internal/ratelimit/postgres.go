@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so replicas
+// sharing the database share the limits. Takes from the same bucket are
+// serialized with a row lock, and the time comes from the database, so
+// replicas with drifting clocks agree.
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, p Policy) (Result, error) {
+	if s.sweepDue() {
+		// Full buckets behave like missing ones. Rows locked by a take in
+		// progress are left for the next sweep rather than waited for.
+		if err := s.db.WithContext(ctx).Exec(`DELETE FROM rate_limit_buckets WHERE key IN (
+			SELECT key FROM rate_limit_buckets WHERE full_at <= now() FOR UPDATE SKIP LOCKED)`).Error; err != nil {
+			return Result{}, err
+		}
+	}
+
+	var result Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at) VALUES (?, ?, now(), now())
+			ON CONFLICT (key) DO NOTHING`, key, p.Burst).Error; err != nil {
+			return err
+		}
+
+		var row struct {
+			Tokens    float64
+			UpdatedAt time.Time
+			Now       time.Time
+		}
+		if err := tx.Raw("SELECT tokens, updated_at, now() AS now FROM rate_limit_buckets WHERE key = ? FOR UPDATE", key).
+			Scan(&row).Error; err != nil {
+			return err
+		}
+
+		var b bucket
+		b, result = take(bucket{tokens: row.Tokens, updated: row.UpdatedAt}, p, row.Now)
+		return tx.Exec("UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, full_at = ? WHERE key = ?",
+			b.tokens, b.updated, b.updated.Add(result.Reset), key).Error
+	})
+	return result, err
+}
+
+func (s *PostgresStore) sweepDue() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		return false
+	}
+	s.lastSweep = time.Now()
+	return true
+}
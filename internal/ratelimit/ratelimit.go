@@ -0,0 +1,181 @@
+// Package ratelimit throttles callers with token buckets. Buckets live in
+// memory or, so that limits hold across replicas, in PostgreSQL.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route groups that share a policy.
+const (
+	GroupRead   = "read"
+	GroupWrite  = "write"
+	GroupCreate = "create"
+)
+
+var Groups = []string{GroupRead, GroupWrite, GroupCreate}
+
+// Policy is a token bucket holding up to Burst tokens and refilled with
+// Limit tokens every Period. Every request takes one token.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// ParsePolicy reads a policy written as "limit/period[:burst]", such as
+// "10/m:5" or "100/10s". The period's count may be left out; the burst
+// defaults to the limit.
+func ParsePolicy(s string) (Policy, error) {
+	invalid := fmt.Errorf("invalid rate limit %q, expected \"limit/period[:burst]\" such as \"10/m:5\"", s)
+
+	limit, rest, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Policy{}, invalid
+	}
+	period, burst, hasBurst := strings.Cut(rest, ":")
+
+	var p Policy
+	var err error
+	if p.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil || p.Limit <= 0 {
+		return Policy{}, invalid
+	}
+	period = strings.TrimSpace(period)
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	if p.Period, err = time.ParseDuration(period); err != nil || p.Period <= 0 {
+		return Policy{}, invalid
+	}
+	p.Burst = p.Limit
+	if hasBurst {
+		if p.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || p.Burst <= 0 {
+			return Policy{}, invalid
+		}
+	}
+	return p, nil
+}
+
+func (p Policy) String() string {
+	s := fmt.Sprintf("%d/%s", p.Limit, formatPeriod(p.Period))
+	if p.Burst != p.Limit {
+		s += ":" + strconv.Itoa(p.Burst)
+	}
+	return s
+}
+
+// formatPeriod writes whole hours, minutes and seconds the way ParsePolicy
+// reads them: "m" for a minute, "10s" for ten seconds.
+func formatPeriod(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if d%unit.d != 0 {
+			continue
+		}
+		if n := d / unit.d; n != 1 {
+			return strconv.FormatInt(int64(n), 10) + unit.name
+		}
+		return unit.name
+	}
+	return d.String()
+}
+
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Policy) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// rate is the number of tokens added per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+type Result struct {
+	Policy  Policy
+	Allowed bool
+	// Remaining is the number of requests that would be allowed right now.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, set when the request was
+	// not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets.
+type Store interface {
+	// Take refills the bucket named key and takes a token from it. A bucket
+	// that does not exist yet starts full.
+	Take(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+// bucket is the state of one token bucket.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newBucket(p Policy, now time.Time) bucket {
+	return bucket{tokens: float64(p.Burst), updated: now}
+}
+
+// take refills b for the time since it was last updated and takes a token if
+// there is a whole one.
+func take(b bucket, p Policy, now time.Time) (bucket, Result) {
+	capacity, rate := float64(p.Burst), p.rate()
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{Policy: p}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return b, result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Limiter applies a policy per route group.
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+func NewLimiter(store Store, policies map[string]Policy) *Limiter {
+	return &Limiter{store: store, policies: policies}
+}
+
+// Take takes a token for the caller identified by key from the bucket of
+// group. ok is false when the group is not limited.
+func (l *Limiter) Take(ctx context.Context, group, key string) (result Result, ok bool, err error) {
+	p, ok := l.policies[group]
+	if !ok {
+		return Result{}, false, nil
+	}
+	result, err = l.store.Take(ctx, group+":"+key, p)
+	return result, true, err
+}
@@ -1,44 +1,169 @@
 package repository
 
 import (
+	"context"
+
 	"qa-service/internal/models"
+	"qa-service/internal/pagination"
 
 	"gorm.io/gorm"
 )
 
-type AnswerRepository struct {
+type answerRepository struct {
 	db *gorm.DB
 }
 
-func NewAnswerRepository(db *gorm.DB) *AnswerRepository {
-	return &AnswerRepository{db: db}
+func NewAnswerRepository(db *gorm.DB) AnswerRepository {
+	return &answerRepository{db: db}
 }
 
-func (r *AnswerRepository) Create(answer *models.Answer) error {
-	return r.db.Create(answer).Error
+func (r *answerRepository) Create(ctx context.Context, answer *models.Answer) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(answer).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Question{}).Where("id = ?", answer.QuestionID).
+			UpdateColumns(map[string]interface{}{"answer_count": gorm.Expr("answer_count + 1"), "updated_at": now()}).Error
+	}))
 }
 
-func (r *AnswerRepository) GetByID(id uint) (*models.Answer, error) {
+func (r *answerRepository) GetByID(ctx context.Context, id uint) (*models.Answer, error) {
 	var answer models.Answer
-	err := r.db.Preload("Question").First(&answer, id).Error
+	err := r.db.WithContext(ctx).Preload("Question").First(&answer, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &answer, nil
 }
 
-func (r *AnswerRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Answer{}, id).Error
+func (r *answerRepository) Update(ctx context.Context, answer *models.Answer, revision *models.Revision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updatedAt := now()
+		result := tx.Model(&models.Answer{}).
+			Where("id = ? AND version = ?", answer.ID, answer.Version).
+			Updates(map[string]interface{}{
+				"text":       answer.Text,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": updatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := touchQuestion(tx, answer.QuestionID, updatedAt); err != nil {
+			return err
+		}
+
+		answer.Version++
+		answer.UpdatedAt = updatedAt
+		if answer.Question.ID == answer.QuestionID {
+			answer.Question.UpdatedAt = updatedAt
+		}
+		return nil
+	}))
 }
 
-func (r *AnswerRepository) GetByQuestionID(questionID uint) ([]models.Answer, error) {
+func (r *answerRepository) Delete(ctx context.Context, id uint, version *int) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var answer models.Answer
+		if err := tx.Select("id", "question_id").First(&answer, id).Error; err != nil {
+			return err
+		}
+		query := tx
+		if version != nil {
+			query = tx.Where("version = ?", *version)
+		}
+		result := query.Delete(&answer)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return missingOrStale(tx, &models.Answer{}, id, version)
+		}
+		if err := tx.Model(&models.Question{}).Where("accepted_answer_id = ?", id).
+			UpdateColumn("accepted_answer_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Question{}).Where("id = ?", answer.QuestionID).
+			UpdateColumns(map[string]interface{}{"answer_count": gorm.Expr("answer_count - 1"), "updated_at": now()}).Error
+	}))
+}
+
+func (r *answerRepository) GetByQuestionID(ctx context.Context, questionID uint) ([]models.Answer, error) {
+	var answers []models.Answer
+	err := r.db.WithContext(ctx).Where("question_id = ?", questionID).Order("created_at ASC").Find(&answers).Error
+	return answers, translateError(err)
+}
+
+func (r *answerRepository) ListByQuestionID(ctx context.Context, questionID uint, opts models.AnswerListOptions) (*models.AnswerPage, error) {
+	filter := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("question_id = ?", questionID)
+		if opts.UserID != "" {
+			query = query.Where("user_id = ?", opts.UserID)
+		}
+		return query
+	}
+
+	query := filter(r.db.WithContext(ctx).Model(&models.Answer{}))
+	switch opts.Order {
+	case models.AnswerOrderNewest:
+		if opts.Cursor != nil {
+			query = query.Where("(created_at, id) < (?, ?)", opts.Cursor.Time, opts.Cursor.ID)
+		}
+		query = query.Order("created_at DESC").Order("id DESC")
+	case models.AnswerOrderUser:
+		if opts.Cursor != nil {
+			query = query.Where("(user_id, created_at, id) > (?, ?, ?)", opts.Cursor.Text, opts.Cursor.Time, opts.Cursor.ID)
+		}
+		query = query.Order("user_id ASC").Order("created_at ASC").Order("id ASC")
+	case models.AnswerOrderScore:
+		if opts.Cursor != nil {
+			query = query.Where("(score < ? OR (score = ? AND id > ?))", opts.Cursor.Number, opts.Cursor.Number, opts.Cursor.ID)
+		}
+		query = query.Order("score DESC").Order("id ASC")
+	default:
+		if opts.Cursor != nil {
+			query = query.Where("(created_at, id) > (?, ?)", opts.Cursor.Time, opts.Cursor.ID)
+		}
+		query = query.Order("created_at ASC").Order("id ASC")
+	}
+
 	var answers []models.Answer
-	err := r.db.Where("question_id = ?", questionID).Order("created_at ASC").Find(&answers).Error
-	return answers, err
+	if err := query.Limit(opts.Limit + 1).Find(&answers).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	var total int64
+	if err := filter(r.db.WithContext(ctx).Model(&models.Answer{})).Count(&total).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return newAnswerPage(answers, opts, total), nil
+}
+
+func newAnswerPage(answers []models.Answer, opts models.AnswerListOptions, total int64) *models.AnswerPage {
+	page := &models.AnswerPage{Items: answers, Total: total}
+	if len(answers) > opts.Limit {
+		page.Items = answers[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = pagination.Encode(pagination.Cursor{
+			Sort:   opts.Order,
+			Time:   last.CreatedAt,
+			Number: int64(last.Score),
+			Text:   last.UserID,
+			ID:     last.ID,
+		})
+	}
+	return page
 }
 
-func (r *AnswerRepository) Exists(id uint) (bool, error) {
+func (r *answerRepository) Exists(ctx context.Context, id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Answer{}).Where("id = ?", id).Count(&count).Error
-	return count > 0, err
+	err := r.db.WithContext(ctx).Model(&models.Answer{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, translateError(err)
 }
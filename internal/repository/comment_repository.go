@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	var target interface{} = &models.Question{}
+	if comment.TargetType == models.CommentTargetAnswer {
+		target = &models.Answer{}
+	}
+
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The shared lock keeps the target from being deleted before the
+		// comment is stored, so no comment outlives its parent.
+		var found struct{ ID uint }
+		if err := tx.Model(target).Clauses(clause.Locking{Strength: "SHARE"}).
+			Select("id").Where("id = ?", comment.TargetID).Take(&found).Error; err != nil {
+			return err
+		}
+		return tx.Create(comment).Error
+	}))
+}
+
+func (r *commentRepository) ListByTarget(ctx context.Context, targetType string, targetID uint) ([]models.Comment, error) {
+	comments := make([]models.Comment, 0)
+	err := r.db.WithContext(ctx).Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("created_at ASC").Order("id ASC").
+		Find(&comments).Error
+	return comments, translateError(err)
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).Take(&comment, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Comment{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// deleteQuestionDependents removes the rows that reference a question or its
+// answers without a foreign key of their own: revisions, votes and comments.
+func deleteQuestionDependents(tx *gorm.DB, questionID uint) error {
+	answerIDs := tx.Unscoped().Model(&models.Answer{}).Select("id").Where("question_id = ?", questionID)
+	if err := deleteDependents(tx, models.RevisionEntityAnswer, answerIDs); err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM question_tags WHERE question_id = ?", questionID).Error; err != nil {
+		return err
+	}
+	return deleteDependents(tx, models.RevisionEntityQuestion, questionID)
+}
+
+func deleteDependents(tx *gorm.DB, entityType string, ids interface{}) error {
+	if err := tx.Where("entity_type = ? AND entity_id IN (?)", entityType, ids).Delete(&models.Revision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("target_type = ? AND target_id IN (?)", entityType, ids).Delete(&models.Vote{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("target_type = ? AND target_id IN (?)", entityType, ids).Delete(&models.Comment{}).Error
+}
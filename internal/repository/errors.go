@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"qa-service/internal/apperr"
+
+	"gorm.io/gorm"
+)
+
+// translateError maps GORM and driver errors onto apperr kinds, so that
+// services never depend on gorm.ErrRecordNotFound or on how a lost
+// connection surfaces in the driver. A query cut short by its context is
+// reported as a timeout when the deadline passed and as unavailable when the
+// request was cancelled, which happens on client disconnect and shutdown.
+func translateError(err error) error {
+	var appErr *apperr.Error
+	var netErr net.Error
+	switch {
+	case err == nil, errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperr.ErrNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperr.Timeout(err)
+	case errors.Is(err, context.Canceled):
+		return apperr.Unavailable(err)
+	case errors.Is(err, driver.ErrBadConn), errors.As(err, &netErr):
+		return apperr.Unavailable(err)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryAnswerRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAnswerRepository(store *MemoryStore) AnswerRepository {
+	return &memoryAnswerRepository{store: store}
+}
+
+func (r *memoryAnswerRepository) Create(_ context.Context, answer *models.Answer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	question, ok := r.store.questions[answer.QuestionID]
+	if !ok {
+		return fmt.Errorf("question %d does not exist", answer.QuestionID)
+	}
+
+	r.store.nextAnswerID++
+	answer.ID = r.store.nextAnswerID
+	if answer.CreatedAt.IsZero() {
+		answer.CreatedAt = now()
+	}
+	answer.UpdatedAt = answer.CreatedAt
+	if answer.Version == 0 {
+		answer.Version = 1
+	}
+
+	stored := *answer
+	stored.Question = models.Question{}
+	r.store.answers[stored.ID] = stored
+
+	question.AnswerCount++
+	question.UpdatedAt = now()
+	r.store.questions[question.ID] = question
+	return nil
+}
+
+func (r *memoryAnswerRepository) GetByID(_ context.Context, id uint) (*models.Answer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	answer, ok := r.store.answers[id]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	answer.Question = r.store.questions[answer.QuestionID]
+	return &answer, nil
+}
+
+func (r *memoryAnswerRepository) Update(_ context.Context, answer *models.Answer, revision *models.Revision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.answers[answer.ID]
+	if !ok || stored.Version != answer.Version {
+		return ErrVersionConflict
+	}
+
+	r.store.addRevision(revision)
+	stored.Text = answer.Text
+	stored.Version++
+	stored.UpdatedAt = now()
+	r.store.answers[stored.ID] = stored
+	r.store.touchQuestion(stored.QuestionID, stored.UpdatedAt)
+
+	answer.Version = stored.Version
+	answer.UpdatedAt = stored.UpdatedAt
+	if answer.Question.ID == answer.QuestionID {
+		answer.Question.UpdatedAt = stored.UpdatedAt
+	}
+	return nil
+}
+
+func (r *memoryAnswerRepository) Delete(_ context.Context, id uint, version *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	answer, ok := r.store.answers[id]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+	if version != nil && answer.Version != *version {
+		return ErrVersionConflict
+	}
+	delete(r.store.answers, id)
+	answer.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	r.store.deletedAnswers[id] = answer
+
+	if question, ok := r.store.questions[answer.QuestionID]; ok {
+		question.AnswerCount--
+		if question.AcceptedAnswerID != nil && *question.AcceptedAnswerID == id {
+			question.AcceptedAnswerID = nil
+		}
+		question.UpdatedAt = answer.DeletedAt.Time
+		r.store.questions[question.ID] = question
+	}
+	return nil
+}
+
+func (r *memoryAnswerRepository) GetByQuestionID(_ context.Context, questionID uint) ([]models.Answer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.answersOf(questionID), nil
+}
+
+func (r *memoryAnswerRepository) ListByQuestionID(_ context.Context, questionID uint, opts models.AnswerListOptions) (*models.AnswerPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	answers := make([]models.Answer, 0)
+	for _, answer := range r.store.answersOf(questionID) {
+		if opts.UserID == "" || answer.UserID == opts.UserID {
+			answers = append(answers, answer)
+		}
+	}
+	total := int64(len(answers))
+
+	less := func(a, b models.Answer) bool {
+		if opts.Order == models.AnswerOrderUser && a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if opts.Order == models.AnswerOrderScore {
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.ID < b.ID
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	if opts.Order == models.AnswerOrderNewest {
+		ascending := less
+		less = func(a, b models.Answer) bool { return ascending(b, a) }
+	}
+	sort.Slice(answers, func(i, j int) bool { return less(answers[i], answers[j]) })
+
+	if opts.Cursor != nil {
+		last := models.Answer{ID: opts.Cursor.ID, CreatedAt: opts.Cursor.Time, Score: int(opts.Cursor.Number), UserID: opts.Cursor.Text}
+		start := sort.Search(len(answers), func(i int) bool { return less(last, answers[i]) })
+		answers = answers[start:]
+	}
+	if len(answers) > opts.Limit+1 {
+		answers = answers[:opts.Limit+1]
+	}
+
+	return newAnswerPage(answers, opts, total), nil
+}
+
+func (r *memoryAnswerRepository) Exists(_ context.Context, id uint) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.answers[id]
+	return ok, nil
+}
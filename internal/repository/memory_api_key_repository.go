@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryAPIKeyRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAPIKeyRepository(store *MemoryStore) APIKeyRepository {
+	return &memoryAPIKeyRepository{store: store}
+}
+
+func (r *memoryAPIKeyRepository) Create(_ context.Context, key *models.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.apiKeys {
+		if existing.KeyHash == key.KeyHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	r.store.nextAPIKeyID++
+	key.ID = r.store.nextAPIKeyID
+	key.CreatedAt = now()
+	r.store.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) GetByHash(_ context.Context, hash string) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, key := range r.store.apiKeys {
+		if key.KeyHash == hash && key.RevokedAt == nil {
+			return &key, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
+
+func (r *memoryAPIKeyRepository) Revoke(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || key.RevokedAt != nil {
+		return apperr.ErrNotFound
+	}
+	revokedAt := now()
+	key.RevokedAt = &revokedAt
+	r.store.apiKeys[id] = key
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+)
+
+type memoryCommentRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCommentRepository(store *MemoryStore) CommentRepository {
+	return &memoryCommentRepository{store: store}
+}
+
+func (r *memoryCommentRepository) Create(_ context.Context, comment *models.Comment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	_, isQuestion := r.store.questions[comment.TargetID]
+	_, isAnswer := r.store.answers[comment.TargetID]
+	if comment.TargetType == models.CommentTargetQuestion && !isQuestion ||
+		comment.TargetType == models.CommentTargetAnswer && !isAnswer {
+		return apperr.ErrNotFound
+	}
+
+	r.store.nextCommentID++
+	comment.ID = r.store.nextCommentID
+	comment.CreatedAt = now()
+	r.store.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r *memoryCommentRepository) ListByTarget(_ context.Context, targetType string, targetID uint) ([]models.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := make([]models.Comment, 0)
+	for _, comment := range r.store.comments {
+		if comment.TargetType == targetType && comment.TargetID == targetID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r *memoryCommentRepository) GetByID(_ context.Context, id uint) (*models.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	return &comment, nil
+}
+
+func (r *memoryCommentRepository) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.comments[id]; !ok {
+		return apperr.ErrNotFound
+	}
+	delete(r.store.comments, id)
+	return nil
+}
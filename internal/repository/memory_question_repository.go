@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryQuestionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryQuestionRepository(store *MemoryStore) QuestionRepository {
+	return &memoryQuestionRepository{store: store}
+}
+
+func (r *memoryQuestionRepository) Create(_ context.Context, question *models.Question) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.nextQuestionID++
+	question.ID = r.store.nextQuestionID
+	if question.CreatedAt.IsZero() {
+		question.CreatedAt = now()
+	}
+	question.UpdatedAt = question.CreatedAt
+	if question.Version == 0 {
+		question.Version = 1
+	}
+
+	stored := *question
+	stored.Answers = nil
+	stored.Tags = nil
+	r.store.questions[stored.ID] = stored
+	r.store.setTags(stored.ID, tagNames(question.Tags))
+	*question = r.store.withTags(*question)
+	return nil
+}
+
+func (r *memoryQuestionRepository) List(_ context.Context, opts models.QuestionListOptions) (*models.QuestionPage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	questions := make([]models.Question, 0, len(r.store.questions))
+	for _, question := range r.store.questions {
+		if matchesQuestionFilters(question, r.store.questionTags[question.ID], opts) {
+			questions = append(questions, r.store.withTags(question))
+		}
+	}
+	total := int64(len(questions))
+
+	less := func(a, b models.Question) bool {
+		if opts.Sort == models.QuestionSortAnswers {
+			if a.AnswerCount != b.AnswerCount {
+				return a.AnswerCount < b.AnswerCount
+			}
+		} else if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	if opts.Order != models.SortAsc {
+		ascending := less
+		less = func(a, b models.Question) bool { return ascending(b, a) }
+	}
+	sort.Slice(questions, func(i, j int) bool { return less(questions[i], questions[j]) })
+
+	if opts.Cursor != nil {
+		last := models.Question{
+			ID:          opts.Cursor.ID,
+			CreatedAt:   opts.Cursor.Time,
+			AnswerCount: int(opts.Cursor.Number),
+		}
+		start := sort.Search(len(questions), func(i int) bool { return less(last, questions[i]) })
+		questions = questions[start:]
+	}
+	if len(questions) > opts.Limit+1 {
+		questions = questions[:opts.Limit+1]
+	}
+
+	return newQuestionPage(questions, opts, total), nil
+}
+
+func matchesQuestionFilters(question models.Question, tags []string, opts models.QuestionListOptions) bool {
+	if opts.CreatedAfter != nil && !question.CreatedAt.After(*opts.CreatedAfter) {
+		return false
+	}
+	if opts.CreatedBefore != nil && !question.CreatedAt.Before(*opts.CreatedBefore) {
+		return false
+	}
+	if opts.Unanswered && question.AnswerCount > 0 {
+		return false
+	}
+	if len(opts.Tags) > 0 {
+		wanted := sortedUnique(opts.Tags)
+		matched := 0
+		for _, name := range wanted {
+			for _, tag := range tags {
+				if tag == name {
+					matched++
+				}
+			}
+		}
+		if matched == 0 || opts.TagMode != models.TagModeAny && matched < len(wanted) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryQuestionRepository) GetByID(_ context.Context, id uint) (*models.Question, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	question, ok := r.store.questions[id]
+	if !ok {
+		return nil, apperr.ErrNotFound
+	}
+	question = r.store.withTags(question)
+	return &question, nil
+}
+
+func (r *memoryQuestionRepository) Update(_ context.Context, question *models.Question, tags []string, revision *models.Revision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.questions[question.ID]
+	if !ok || stored.Version != question.Version {
+		return ErrVersionConflict
+	}
+
+	r.store.addRevision(revision)
+	stored.Text = question.Text
+	stored.Version++
+	stored.UpdatedAt = now()
+	r.store.questions[stored.ID] = stored
+	if tags != nil {
+		r.store.setTags(stored.ID, tags)
+		question.Tags = r.store.withTags(stored).Tags
+	}
+
+	question.Version = stored.Version
+	question.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *memoryQuestionRepository) SetAcceptedAnswer(_ context.Context, question *models.Question, answerID *uint, version *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.questions[question.ID]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+	if version != nil && stored.Version != *version {
+		return ErrVersionConflict
+	}
+	if answerID != nil {
+		answer, ok := r.store.answers[*answerID]
+		if !ok || answer.QuestionID != question.ID {
+			return apperr.ErrNotFound
+		}
+		accepted := *answerID
+		answerID = &accepted
+	}
+
+	stored.AcceptedAnswerID = answerID
+	stored.UpdatedAt = now()
+	r.store.questions[stored.ID] = stored
+
+	question.AcceptedAnswerID = stored.AcceptedAnswerID
+	question.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *memoryQuestionRepository) Delete(_ context.Context, id uint, version *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	question, ok := r.store.questions[id]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+	if version != nil && question.Version != *version {
+		return ErrVersionConflict
+	}
+	deletedAt := gorm.DeletedAt{Time: now(), Valid: true}
+
+	delete(r.store.questions, id)
+	question.DeletedAt = deletedAt
+	r.store.deletedQuestions[id] = question
+	for answerID, answer := range r.store.answers {
+		if answer.QuestionID == id {
+			delete(r.store.answers, answerID)
+			answer.DeletedAt = deletedAt
+			r.store.deletedAnswers[answerID] = answer
+		}
+	}
+	return nil
+}
+
+func (r *memoryQuestionRepository) Exists(_ context.Context, id uint) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.questions[id]
+	return ok, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+)
+
+type memoryRevisionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRevisionRepository(store *MemoryStore) RevisionRepository {
+	return &memoryRevisionRepository{store: store}
+}
+
+func (r *memoryRevisionRepository) ListByEntity(_ context.Context, entityType string, entityID uint) ([]models.Revision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	revisions := make([]models.Revision, 0)
+	for _, revision := range r.store.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Version < revisions[j].Version })
+	return revisions, nil
+}
+
+func (r *memoryRevisionRepository) GetByVersion(_ context.Context, entityType string, entityID uint, version int) (*models.Revision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, revision := range r.store.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID && revision.Version == version {
+			return &revision, nil
+		}
+	}
+	return nil, apperr.ErrNotFound
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"qa-service/internal/models"
+)
+
+const memorySnippetWords = 35
+
+// memorySearchRepository approximates the Postgres full-text search: every
+// query term has to match the beginning of a word, which stands in for
+// stemming, and the rank is the share of matching words.
+type memorySearchRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySearchRepository(store *MemoryStore) SearchRepository {
+	return &memorySearchRepository{store: store}
+}
+
+func (r *memorySearchRepository) Search(_ context.Context, opts models.SearchOptions) ([]models.SearchResult, error) {
+	terms := splitWords(strings.ToLower(opts.Query))
+	results := make([]models.SearchResult, 0)
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	r.store.mu.RLock()
+	if opts.Type == "" || opts.Type == models.SearchTypeQuestion {
+		for _, question := range r.store.questions {
+			if result, ok := matchText(question.Text, terms); ok {
+				result.Type = models.SearchTypeQuestion
+				result.ID = question.ID
+				result.QuestionID = question.ID
+				result.CreatedAt = question.CreatedAt
+				results = append(results, result)
+			}
+		}
+	}
+	if opts.Type == "" || opts.Type == models.SearchTypeAnswer {
+		for _, answer := range r.store.answers {
+			if result, ok := matchText(answer.Text, terms); ok {
+				result.Type = models.SearchTypeAnswer
+				result.ID = answer.ID
+				result.QuestionID = answer.QuestionID
+				result.CreatedAt = answer.CreatedAt
+				results = append(results, result)
+			}
+		}
+	}
+	r.store.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.After(results[j].CreatedAt)
+		}
+		return results[i].ID > results[j].ID
+	})
+
+	if opts.Offset >= len(results) {
+		return results[:0], nil
+	}
+	results = results[opts.Offset:]
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+func matchText(text string, terms []string) (models.SearchResult, bool) {
+	words := strings.Fields(text)
+	matched := make([]bool, len(words))
+	hits := 0
+
+	for _, term := range terms {
+		found := false
+		for i, word := range words {
+			for _, part := range splitWords(strings.ToLower(word)) {
+				if strings.HasPrefix(part, term) {
+					matched[i] = true
+					found = true
+					hits++
+					break
+				}
+			}
+		}
+		if !found {
+			return models.SearchResult{}, false
+		}
+	}
+
+	first := 0
+	for first < len(matched) && !matched[first] {
+		first++
+	}
+	start := first - memorySnippetWords/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + memorySnippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	snippet := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		if matched[i] {
+			snippet = append(snippet, "<mark>"+html.EscapeString(words[i])+"</mark>")
+		} else {
+			snippet = append(snippet, html.EscapeString(words[i]))
+		}
+	}
+
+	return models.SearchResult{
+		Snippet: strings.Join(snippet, " "),
+		Rank:    float64(hits) / float64(len(words)),
+	}, true
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
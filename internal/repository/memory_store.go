@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"qa-service/internal/models"
+)
+
+// MemoryStore holds the state shared by the in-memory repositories so that
+// questions and answers can be cascaded the same way the database does it.
+// Deleted questions and answers are kept apart from live ones until purged.
+type MemoryStore struct {
+	mu               sync.RWMutex
+	questions        map[uint]models.Question
+	answers          map[uint]models.Answer
+	deletedQuestions map[uint]models.Question
+	deletedAnswers   map[uint]models.Answer
+	revisions        map[uint]models.Revision
+	votes            map[voteKey]models.Vote
+	tags             map[string]models.Tag
+	questionTags     map[uint][]string
+	apiKeys          map[uint]models.APIKey
+	comments         map[uint]models.Comment
+	nextQuestionID   uint
+	nextAnswerID     uint
+	nextRevisionID   uint
+	nextVoteID       uint
+	nextTagID        uint
+	nextAPIKeyID     uint
+	nextCommentID    uint
+}
+
+type voteKey struct {
+	userID     string
+	targetType string
+	targetID   uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		questions: make(map[uint]models.Question),
+		answers:   make(map[uint]models.Answer),
+		revisions: make(map[uint]models.Revision),
+		votes:     make(map[voteKey]models.Vote),
+		tags:      make(map[string]models.Tag),
+		apiKeys:   make(map[uint]models.APIKey),
+		comments:  make(map[uint]models.Comment),
+
+		questionTags:     make(map[uint][]string),
+		deletedQuestions: make(map[uint]models.Question),
+		deletedAnswers:   make(map[uint]models.Answer),
+	}
+}
+
+func (s *MemoryStore) answersOf(questionID uint) []models.Answer {
+	answers := make([]models.Answer, 0)
+	for _, answer := range s.answers {
+		if answer.QuestionID == questionID {
+			answers = append(answers, answer)
+		}
+	}
+	sort.Slice(answers, func(i, j int) bool {
+		if !answers[i].CreatedAt.Equal(answers[j].CreatedAt) {
+			return answers[i].CreatedAt.Before(answers[j].CreatedAt)
+		}
+		return answers[i].ID < answers[j].ID
+	})
+	return answers
+}
+
+func (s *MemoryStore) addRevision(revision *models.Revision) {
+	s.nextRevisionID++
+	revision.ID = s.nextRevisionID
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = now()
+	}
+	s.revisions[revision.ID] = *revision
+}
+
+func (s *MemoryStore) setTags(questionID uint, names []string) {
+	for _, name := range names {
+		if _, ok := s.tags[name]; !ok {
+			s.nextTagID++
+			s.tags[name] = models.Tag{ID: s.nextTagID, Name: name, CreatedAt: now()}
+		}
+	}
+	s.questionTags[questionID] = sortedUnique(names)
+}
+
+func (s *MemoryStore) withTags(question models.Question) models.Question {
+	question.Tags = make([]models.Tag, 0, len(s.questionTags[question.ID]))
+	for _, name := range s.questionTags[question.ID] {
+		question.Tags = append(question.Tags, s.tags[name])
+	}
+	return question
+}
+
+func (s *MemoryStore) replaceTag(name, replacement string) {
+	updatedAt := now()
+	for questionID, names := range s.questionTags {
+		for i, tag := range names {
+			if tag == name {
+				names[i] = replacement
+				s.questionTags[questionID] = sortedUnique(names)
+				s.touchQuestion(questionID, updatedAt)
+				break
+			}
+		}
+	}
+}
+
+// touchQuestion moves the question's updated_at, which stands for the last
+// change to anything shown with the question.
+func (s *MemoryStore) touchQuestion(id uint, at time.Time) {
+	if question, ok := s.questions[id]; ok {
+		question.UpdatedAt = at
+		s.questions[id] = question
+	}
+}
+
+func sortedUnique(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// deleteDependents removes the rows that reference a question or an answer
+// without a foreign key of their own.
+func (s *MemoryStore) deleteDependents(entityType string, entityID uint) {
+	for id, revision := range s.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID {
+			delete(s.revisions, id)
+		}
+	}
+	for key := range s.votes {
+		if key.targetType == entityType && key.targetID == entityID {
+			delete(s.votes, key)
+		}
+	}
+	for id, comment := range s.comments {
+		if comment.TargetType == entityType && comment.TargetID == entityID {
+			delete(s.comments, id)
+		}
+	}
+}
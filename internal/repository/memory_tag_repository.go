@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+)
+
+type memoryTagRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTagRepository(store *MemoryStore) TagRepository {
+	return &memoryTagRepository{store: store}
+}
+
+func (r *memoryTagRepository) ListUsage(_ context.Context) ([]models.TagUsage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int64, len(r.store.tags))
+	for questionID, names := range r.store.questionTags {
+		if _, ok := r.store.questions[questionID]; !ok {
+			continue
+		}
+		for _, name := range names {
+			counts[name]++
+		}
+	}
+
+	usage := make([]models.TagUsage, 0, len(r.store.tags))
+	for name := range r.store.tags {
+		usage = append(usage, models.TagUsage{Name: name, Count: counts[name]})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage, nil
+}
+
+func (r *memoryTagRepository) Rename(_ context.Context, name, newName string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tag, ok := r.store.tags[name]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+	if _, exists := r.store.tags[newName]; exists {
+		return ErrTagExists
+	}
+
+	delete(r.store.tags, name)
+	tag.Name = newName
+	r.store.tags[newName] = tag
+	r.store.replaceTag(name, newName)
+	return nil
+}
+
+func (r *memoryTagRepository) Merge(_ context.Context, name, into string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[name]; !ok {
+		return apperr.ErrNotFound
+	}
+	if _, ok := r.store.tags[into]; !ok {
+		return apperr.ErrNotFound
+	}
+
+	delete(r.store.tags, name)
+	r.store.replaceTag(name, into)
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryTrashRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTrashRepository(store *MemoryStore) TrashRepository {
+	return &memoryTrashRepository{store: store}
+}
+
+func (r *memoryTrashRepository) List(_ context.Context, opts models.TrashOptions) ([]models.TrashItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	items := make([]models.TrashItem, 0)
+	for _, question := range r.store.deletedQuestions {
+		items = append(items, models.TrashItem{
+			Type:       models.TrashTypeQuestion,
+			ID:         question.ID,
+			QuestionID: question.ID,
+			UserID:     question.UserID,
+			Text:       question.Text,
+			DeletedAt:  question.DeletedAt.Time,
+		})
+	}
+	for _, answer := range r.store.deletedAnswers {
+		if _, live := r.store.questions[answer.QuestionID]; !live {
+			continue
+		}
+		items = append(items, models.TrashItem{
+			Type:       models.TrashTypeAnswer,
+			ID:         answer.ID,
+			QuestionID: answer.QuestionID,
+			UserID:     answer.UserID,
+			Text:       answer.Text,
+			DeletedAt:  answer.DeletedAt.Time,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		return items[i].ID > items[j].ID
+	})
+
+	if opts.Offset >= len(items) {
+		return items[:0], nil
+	}
+	items = items[opts.Offset:]
+	if len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return items, nil
+}
+
+func (r *memoryTrashRepository) RestoreQuestion(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	question, ok := r.store.deletedQuestions[id]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+
+	for answerID, answer := range r.store.deletedAnswers {
+		if answer.QuestionID == id && answer.DeletedAt.Time.Equal(question.DeletedAt.Time) {
+			delete(r.store.deletedAnswers, answerID)
+			answer.DeletedAt = gorm.DeletedAt{}
+			r.store.answers[answerID] = answer
+		}
+	}
+	delete(r.store.deletedQuestions, id)
+	question.DeletedAt = gorm.DeletedAt{}
+	question.UpdatedAt = now()
+	r.store.questions[id] = question
+	return nil
+}
+
+func (r *memoryTrashRepository) RestoreAnswer(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	answer, ok := r.store.deletedAnswers[id]
+	if !ok {
+		return apperr.ErrNotFound
+	}
+	question, ok := r.store.questions[answer.QuestionID]
+	if !ok {
+		return ErrParentDeleted
+	}
+
+	delete(r.store.deletedAnswers, id)
+	answer.DeletedAt = gorm.DeletedAt{}
+	r.store.answers[id] = answer
+	question.AnswerCount++
+	question.UpdatedAt = now()
+	r.store.questions[question.ID] = question
+	return nil
+}
+
+func (r *memoryTrashRepository) Purge(_ context.Context, before time.Time) (*models.PurgeResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := &models.PurgeResult{}
+	for id, question := range r.store.deletedQuestions {
+		if !question.DeletedAt.Time.Before(before) {
+			continue
+		}
+		for answerID, answer := range r.store.deletedAnswers {
+			if answer.QuestionID == id {
+				delete(r.store.deletedAnswers, answerID)
+				r.store.deleteDependents(models.RevisionEntityAnswer, answerID)
+			}
+		}
+		delete(r.store.deletedQuestions, id)
+		delete(r.store.questionTags, id)
+		r.store.deleteDependents(models.RevisionEntityQuestion, id)
+		result.Questions++
+	}
+
+	for id, answer := range r.store.deletedAnswers {
+		if answer.DeletedAt.Time.Before(before) {
+			delete(r.store.deletedAnswers, id)
+			r.store.deleteDependents(models.RevisionEntityAnswer, id)
+			result.Answers++
+		}
+	}
+	return result, nil
+}
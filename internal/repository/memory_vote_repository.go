@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+)
+
+type memoryVoteRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryVoteRepository(store *MemoryStore) VoteRepository {
+	return &memoryVoteRepository{store: store}
+}
+
+func (r *memoryVoteRepository) Cast(_ context.Context, vote *models.Vote) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	question, isQuestion := r.store.questions[vote.TargetID]
+	answer, isAnswer := r.store.answers[vote.TargetID]
+	if vote.TargetType == models.VoteTargetQuestion && !isQuestion ||
+		vote.TargetType == models.VoteTargetAnswer && !isAnswer {
+		return 0, apperr.ErrNotFound
+	}
+
+	key := voteKey{userID: vote.UserID, targetType: vote.TargetType, targetID: vote.TargetID}
+	previous := 0
+	if existing, ok := r.store.votes[key]; ok {
+		previous = existing.Value
+		if vote.Value == 0 {
+			delete(r.store.votes, key)
+		} else {
+			existing.Value = vote.Value
+			existing.UpdatedAt = now()
+			r.store.votes[key] = existing
+			*vote = existing
+		}
+	} else if vote.Value != 0 {
+		r.store.nextVoteID++
+		vote.ID = r.store.nextVoteID
+		vote.CreatedAt = now()
+		vote.UpdatedAt = vote.CreatedAt
+		r.store.votes[key] = *vote
+	}
+
+	delta := vote.Value - previous
+	if vote.TargetType == models.VoteTargetAnswer {
+		if delta != 0 {
+			answer.Score += delta
+			answer.UpdatedAt = now()
+			r.store.answers[answer.ID] = answer
+			r.store.touchQuestion(answer.QuestionID, answer.UpdatedAt)
+		}
+		return answer.Score, nil
+	}
+	if delta != 0 {
+		question.Score += delta
+		question.UpdatedAt = now()
+		r.store.questions[question.ID] = question
+	}
+	return question.Score, nil
+}
@@ -1,44 +1,253 @@
 package repository
 
 import (
+	"context"
+	"time"
+
+	"qa-service/internal/apperr"
 	"qa-service/internal/models"
+	"qa-service/internal/pagination"
 
 	"gorm.io/gorm"
 )
 
-type QuestionRepository struct {
+type questionRepository struct {
 	db *gorm.DB
 }
 
-func NewQuestionRepository(db *gorm.DB) *QuestionRepository {
-	return &QuestionRepository{db: db}
+func NewQuestionRepository(db *gorm.DB) QuestionRepository {
+	return &questionRepository{db: db}
 }
 
-func (r *QuestionRepository) Create(question *models.Question) error {
-	return r.db.Create(question).Error
+func (r *questionRepository) Create(ctx context.Context, question *models.Question) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tags, err := ensureTags(tx, tagNames(question.Tags))
+		if err != nil {
+			return err
+		}
+		question.Tags = tags
+		return tx.Omit("Tags.*").Create(question).Error
+	}))
 }
 
-func (r *QuestionRepository) GetAll() ([]models.Question, error) {
+func (r *questionRepository) List(ctx context.Context, opts models.QuestionListOptions) (*models.QuestionPage, error) {
+	column := "created_at"
+	if opts.Sort == models.QuestionSortAnswers {
+		column = "answer_count"
+	}
+	direction := "DESC"
+	comparison := "<"
+	if opts.Order == models.SortAsc {
+		direction = "ASC"
+		comparison = ">"
+	}
+
+	query := applyQuestionFilters(r.db.WithContext(ctx).Model(&models.Question{}), opts).Preload("Tags", orderTags)
+	if opts.Cursor != nil {
+		var key interface{} = opts.Cursor.Time
+		if opts.Sort == models.QuestionSortAnswers {
+			key = opts.Cursor.Number
+		}
+		query = query.Where("("+column+", id) "+comparison+" (?, ?)", key, opts.Cursor.ID)
+	}
+
 	var questions []models.Question
-	err := r.db.Order("created_at DESC").Find(&questions).Error
-	return questions, err
+	err := query.Order(column + " " + direction).Order("id " + direction).Limit(opts.Limit + 1).Find(&questions).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	total, err := r.estimateTotal(ctx, opts)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return newQuestionPage(questions, opts, total), nil
 }
 
-func (r *QuestionRepository) GetByID(id uint) (*models.Question, error) {
+func (r *questionRepository) estimateTotal(ctx context.Context, opts models.QuestionListOptions) (int64, error) {
+	if !opts.Filtered() {
+		var estimate int64
+		err := r.db.WithContext(ctx).Raw("SELECT reltuples::bigint FROM pg_class WHERE oid = 'questions'::regclass").Scan(&estimate).Error
+		if err != nil {
+			return 0, err
+		}
+		if estimate >= 0 {
+			return estimate, nil
+		}
+	}
+
+	var count int64
+	err := applyQuestionFilters(r.db.WithContext(ctx).Model(&models.Question{}), opts).Count(&count).Error
+	return count, translateError(err)
+}
+
+func applyQuestionFilters(query *gorm.DB, opts models.QuestionListOptions) *gorm.DB {
+	if opts.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *opts.CreatedBefore)
+	}
+	if opts.Unanswered {
+		query = query.Where("answer_count = 0")
+	}
+	if len(opts.Tags) > 0 {
+		tagged := query.Session(&gorm.Session{NewDB: true}).Table("question_tags").
+			Select("question_tags.question_id").
+			Joins("JOIN tags ON tags.id = question_tags.tag_id").
+			Where("tags.name IN ?", opts.Tags)
+		if opts.TagMode != models.TagModeAny {
+			tagged = tagged.Group("question_tags.question_id").Having("COUNT(DISTINCT tags.id) = ?", len(opts.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	return query
+}
+
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name ASC")
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func newQuestionPage(questions []models.Question, opts models.QuestionListOptions, total int64) *models.QuestionPage {
+	page := &models.QuestionPage{Items: questions, TotalEstimate: total}
+	if len(questions) > opts.Limit {
+		page.Items = questions[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = pagination.Encode(pagination.Cursor{
+			Sort:   opts.Sort,
+			Order:  opts.Order,
+			Time:   last.CreatedAt,
+			Number: int64(last.AnswerCount),
+			ID:     last.ID,
+		})
+	}
+	return page
+}
+
+func (r *questionRepository) GetByID(ctx context.Context, id uint) (*models.Question, error) {
 	var question models.Question
-	err := r.db.Preload("Answers").First(&question, id).Error
+	err := r.db.WithContext(ctx).Preload("Tags", orderTags).First(&question, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &question, nil
 }
 
-func (r *QuestionRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Question{}, id).Error
+func (r *questionRepository) Update(ctx context.Context, question *models.Question, tags []string, revision *models.Revision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updatedAt := now()
+		result := tx.Model(&models.Question{}).
+			Where("id = ? AND version = ?", question.ID, question.Version).
+			Updates(map[string]interface{}{
+				"text":       question.Text,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": updatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if tags != nil {
+			stored, err := ensureTags(tx, tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Question{ID: question.ID}).Association("Tags").Replace(stored); err != nil {
+				return err
+			}
+			question.Tags = stored
+		}
+
+		question.Version++
+		question.UpdatedAt = updatedAt
+		return nil
+	}))
 }
 
-func (r *QuestionRepository) Exists(id uint) (bool, error) {
+func (r *questionRepository) SetAcceptedAnswer(ctx context.Context, question *models.Question, answerID *uint, version *int) error {
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.Question{}).Where("id = ?", question.ID)
+	if answerID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM answers WHERE answers.id = ? AND answers.question_id = questions.id AND answers.deleted_at IS NULL)", *answerID)
+	}
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+
+	updatedAt := now()
+	result := query.UpdateColumns(map[string]interface{}{"accepted_answer_id": answerID, "updated_at": updatedAt})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return translateError(missingOrStale(db, &models.Question{}, question.ID, version))
+	}
+
+	question.AcceptedAnswerID = answerID
+	question.UpdatedAt = updatedAt
+	return nil
+}
+
+// Delete moves the question and its live answers to the trash. The answers
+// get the question's deletion time, which is how RestoreQuestion tells them
+// apart from answers that had been deleted on their own.
+func (r *questionRepository) Delete(ctx context.Context, id uint, version *int) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deletedAt := time.Now()
+		query := tx.Model(&models.Question{}).Where("id = ?", id)
+		if version != nil {
+			query = query.Where("version = ?", *version)
+		}
+		result := query.UpdateColumn("deleted_at", deletedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return missingOrStale(tx, &models.Question{}, id, version)
+		}
+		return tx.Model(&models.Answer{}).Where("question_id = ?", id).UpdateColumn("deleted_at", deletedAt).Error
+	}))
+}
+
+func (r *questionRepository) Exists(ctx context.Context, id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Question{}).Where("id = ?", id).Count(&count).Error
-	return count > 0, err
+	err := r.db.WithContext(ctx).Model(&models.Question{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, translateError(err)
+}
+
+// missingOrStale tells why a write to the row with id, guarded by version,
+// matched nothing: ErrVersionConflict when the row is there at another
+// version, apperr.ErrNotFound otherwise.
+func missingOrStale(tx *gorm.DB, model interface{}, id uint, version *int) error {
+	if version != nil {
+		var count int64
+		if err := tx.Model(model).Where("id = ? AND version <> ?", id, *version).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrVersionConflict
+		}
+	}
+	return apperr.ErrNotFound
+}
+
+// touchQuestion moves the question's updated_at, which stands for the last
+// change to anything shown with the question: its answers, votes, tags and
+// accepted answer as well as its text.
+func touchQuestion(tx *gorm.DB, id uint, at time.Time) error {
+	return tx.Model(&models.Question{}).Where("id = ?", id).UpdateColumn("updated_at", at).Error
 }
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned by writes guarded by a version when the
+// stored version no longer matches the one the caller read.
+var ErrVersionConflict = errors.New("version conflict")
+
+type QuestionRepository interface {
+	Create(ctx context.Context, question *models.Question) error
+	List(ctx context.Context, opts models.QuestionListOptions) (*models.QuestionPage, error)
+	GetByID(ctx context.Context, id uint) (*models.Question, error)
+	// Update stores the question's text and, unless tags is nil, replaces its
+	// tags, bumping the version and adding revision in the same transaction.
+	Update(ctx context.Context, question *models.Question, tags []string, revision *models.Revision) error
+	// SetAcceptedAnswer marks answerID as accepted, or clears the mark when it
+	// is nil, and updates question to match. The answer has to belong to the
+	// question and, unless version is nil, the question has to be at that
+	// version.
+	SetAcceptedAnswer(ctx context.Context, question *models.Question, answerID *uint, version *int) error
+	// Delete moves the question to the trash together with its answers.
+	// Unless version is nil, the question has to be at that version.
+	Delete(ctx context.Context, id uint, version *int) error
+	Exists(ctx context.Context, id uint) (bool, error)
+}
+
+type AnswerRepository interface {
+	Create(ctx context.Context, answer *models.Answer) error
+	GetByID(ctx context.Context, id uint) (*models.Answer, error)
+	Update(ctx context.Context, answer *models.Answer, revision *models.Revision) error
+	// Delete moves the answer to the trash. Unless version is nil, the answer
+	// has to be at that version.
+	Delete(ctx context.Context, id uint, version *int) error
+	GetByQuestionID(ctx context.Context, questionID uint) ([]models.Answer, error)
+	ListByQuestionID(ctx context.Context, questionID uint, opts models.AnswerListOptions) (*models.AnswerPage, error)
+	Exists(ctx context.Context, id uint) (bool, error)
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, opts models.SearchOptions) ([]models.SearchResult, error)
+}
+
+type RevisionRepository interface {
+	ListByEntity(ctx context.Context, entityType string, entityID uint) ([]models.Revision, error)
+	GetByVersion(ctx context.Context, entityType string, entityID uint, version int) (*models.Revision, error)
+}
+
+type VoteRepository interface {
+	// Cast stores the vote, or removes it when its value is zero, and
+	// returns the updated score of the target.
+	Cast(ctx context.Context, vote *models.Vote) (int, error)
+}
+
+type TagRepository interface {
+	ListUsage(ctx context.Context) ([]models.TagUsage, error)
+	Rename(ctx context.Context, name, newName string) error
+	// Merge moves every question tagged with name over to into and removes
+	// the name tag.
+	Merge(ctx context.Context, name, into string) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	// GetByHash returns the active key with the given hash.
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	Revoke(ctx context.Context, id uint) error
+}
+
+type CommentRepository interface {
+	// Create fails with apperr.ErrNotFound when the target does not exist.
+	Create(ctx context.Context, comment *models.Comment) error
+	ListByTarget(ctx context.Context, targetType string, targetID uint) ([]models.Comment, error)
+	GetByID(ctx context.Context, id uint) (*models.Comment, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type TrashRepository interface {
+	List(ctx context.Context, opts models.TrashOptions) ([]models.TrashItem, error)
+	// RestoreQuestion brings back a deleted question and the answers that
+	// were deleted along with it.
+	RestoreQuestion(ctx context.Context, id uint) error
+	RestoreAnswer(ctx context.Context, id uint) error
+	// Purge permanently removes everything deleted before the given time.
+	Purge(ctx context.Context, before time.Time) (*models.PurgeResult, error)
+}
+
+type Repositories struct {
+	Questions QuestionRepository
+	Answers   AnswerRepository
+	Search    SearchRepository
+	Revisions RevisionRepository
+	Votes     VoteRepository
+	Tags      TagRepository
+	APIKeys   APIKeyRepository
+	Comments  CommentRepository
+	Trash     TrashRepository
+}
+
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Questions: NewQuestionRepository(db),
+		Answers:   NewAnswerRepository(db),
+		Search:    NewSearchRepository(db),
+		Revisions: NewRevisionRepository(db),
+		Votes:     NewVoteRepository(db),
+		Tags:      NewTagRepository(db),
+		APIKeys:   NewAPIKeyRepository(db),
+		Comments:  NewCommentRepository(db),
+		Trash:     NewTrashRepository(db),
+	}
+}
+
+func NewMemoryRepositories(store *MemoryStore) *Repositories {
+	return &Repositories{
+		Questions: NewMemoryQuestionRepository(store),
+		Answers:   NewMemoryAnswerRepository(store),
+		Search:    NewMemorySearchRepository(store),
+		Revisions: NewMemoryRevisionRepository(store),
+		Votes:     NewMemoryVoteRepository(store),
+		Tags:      NewMemoryTagRepository(store),
+		APIKeys:   NewMemoryAPIKeyRepository(store),
+		Comments:  NewMemoryCommentRepository(store),
+		Trash:     NewMemoryTrashRepository(store),
+	}
+}
+
+// now returns the current time at the microsecond precision Postgres keeps,
+// so that a timestamp handed back after a write equals the one read later.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+func (r *revisionRepository) ListByEntity(ctx context.Context, entityType string, entityID uint) ([]models.Revision, error) {
+	var revisions []models.Revision
+	err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("version ASC").Find(&revisions).Error
+	return revisions, translateError(err)
+}
+
+func (r *revisionRepository) GetByVersion(ctx context.Context, entityType string, entityID uint, version int) (*models.Revision, error) {
+	var revision models.Revision
+	err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).First(&revision).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &revision, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const searchHeadlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "`
+
+// escapedText HTML-escapes the text before ts_headline, so the <mark> tags it
+// adds are the only markup in a snippet. The default parser keeps entities
+// as single tokens, so fragment boundaries never split one.
+const escapedText = `replace(replace(replace(replace(replace(text, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) Search(ctx context.Context, opts models.SearchOptions) ([]models.SearchResult, error) {
+	var sources []string
+	if opts.Type == "" || opts.Type == models.SearchTypeQuestion {
+		sources = append(sources, `SELECT 'question' AS type, id, id AS question_id, text,
+			ts_rank(search_vector, query.q) AS rank, created_at
+			FROM questions, query WHERE search_vector @@ query.q AND deleted_at IS NULL`)
+	}
+	if opts.Type == "" || opts.Type == models.SearchTypeAnswer {
+		sources = append(sources, `SELECT 'answer' AS type, id, question_id, text,
+			ts_rank(search_vector, query.q) AS rank, created_at
+			FROM answers, query WHERE search_vector @@ query.q AND deleted_at IS NULL`)
+	}
+
+	statement := `WITH query AS (SELECT ` + searchQueryExpression(opts.Language) + ` AS q),
+		matches AS (` + strings.Join(sources, " UNION ALL ") + `)
+		SELECT type, id, question_id, ts_headline(@config::regconfig, ` + escapedText + `, query.q, @options) AS snippet, rank, created_at
+		FROM (SELECT * FROM matches ORDER BY rank DESC, created_at DESC, id DESC LIMIT @limit OFFSET @offset) page, query
+		ORDER BY rank DESC, created_at DESC, id DESC`
+
+	results := make([]models.SearchResult, 0)
+	err := r.db.WithContext(ctx).Raw(statement,
+		sql.Named("q", opts.Query),
+		sql.Named("config", headlineConfig(opts)),
+		sql.Named("options", searchHeadlineOptions),
+		sql.Named("limit", opts.Limit),
+		sql.Named("offset", opts.Offset),
+	).Scan(&results).Error
+	return results, translateError(err)
+}
+
+func searchQueryExpression(language string) string {
+	switch language {
+	case models.SearchLanguageRussian:
+		return "websearch_to_tsquery('russian', @q)"
+	case models.SearchLanguageEnglish:
+		return "websearch_to_tsquery('english', @q)"
+	default:
+		return "websearch_to_tsquery('russian', @q) || websearch_to_tsquery('english', @q)"
+	}
+}
+
+// headlineConfig picks a single configuration for ts_headline, which unlike
+// the search vectors cannot combine several. Without an explicit language the
+// script of the query decides.
+func headlineConfig(opts models.SearchOptions) string {
+	switch opts.Language {
+	case models.SearchLanguageRussian:
+		return "russian"
+	case models.SearchLanguageEnglish:
+		return "english"
+	}
+	for _, r := range opts.Query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return "russian"
+		}
+	}
+	return "english"
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTagExists is returned when renaming a tag onto a name that is taken.
+var ErrTagExists = errors.New("tag already exists")
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) ListUsage(ctx context.Context) ([]models.TagUsage, error) {
+	usage := make([]models.TagUsage, 0)
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name, COUNT(questions.id) AS count").
+		Joins("LEFT JOIN question_tags ON question_tags.tag_id = tags.id").
+		Joins("LEFT JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("count DESC").Order("tags.name ASC").
+		Scan(&usage).Error
+	return usage, translateError(err)
+}
+
+func (r *tagRepository) Rename(ctx context.Context, name, newName string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Tag{}).Where("name = ?", newName).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrTagExists
+		}
+
+		result := tx.Model(&models.Tag{}).Where("name = ?", name).Update("name", newName)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.ErrNotFound
+		}
+		return touchTagged(tx, tx.Model(&models.Tag{}).Select("id").Where("name = ?", newName))
+	}))
+}
+
+func (r *tagRepository) Merge(ctx context.Context, name, into string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var source, target models.Tag
+		if err := tx.Where("name = ?", name).Take(&source).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("name = ?", into).Take(&target).Error; err != nil {
+			return err
+		}
+
+		if err := touchTagged(tx, source.ID); err != nil {
+			return err
+		}
+		err := tx.Exec(`INSERT INTO question_tags (question_id, tag_id)
+			SELECT question_id, ? FROM question_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM question_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	}))
+}
+
+// touchTagged moves updated_at of the questions carrying the tag, given as an
+// id or as a subquery selecting one.
+func touchTagged(tx *gorm.DB, tagID interface{}) error {
+	return tx.Model(&models.Question{}).
+		Where("id IN (?)", tx.Table("question_tags").Select("question_id").Where("tag_id = (?)", tagID)).
+		UpdateColumn("updated_at", now()).Error
+}
+
+// ensureTags returns the tags with the given names, creating missing ones.
+func ensureTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name})
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	tags = tags[:0]
+	err = tx.Where("name IN ?", names).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrParentDeleted is returned when restoring an answer whose question is
+// itself in the trash.
+var ErrParentDeleted = errors.New("parent is deleted")
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) List(ctx context.Context, opts models.TrashOptions) ([]models.TrashItem, error) {
+	items := make([]models.TrashItem, 0)
+	err := r.db.WithContext(ctx).Raw(`SELECT 'question' AS type, id, id AS question_id, user_id, text, deleted_at
+			FROM questions WHERE deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'answer' AS type, answers.id, answers.question_id, answers.user_id, answers.text, answers.deleted_at
+			FROM answers JOIN questions ON questions.id = answers.question_id
+			WHERE answers.deleted_at IS NOT NULL AND questions.deleted_at IS NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT ? OFFSET ?`, opts.Limit, opts.Offset).Scan(&items).Error
+	return items, translateError(err)
+}
+
+func (r *trashRepository) RestoreQuestion(ctx context.Context, id uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var question models.Question
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "deleted_at").Where("id = ? AND deleted_at IS NOT NULL", id).Take(&question).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&models.Answer{}).
+			Where("question_id = ? AND deleted_at = ?", id, question.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Question{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{"deleted_at": nil, "updated_at": now()}).Error
+	}))
+}
+
+func (r *trashRepository) RestoreAnswer(ctx context.Context, id uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var answer models.Answer
+		err := tx.Unscoped().Select("id", "question_id").
+			Where("id = ? AND deleted_at IS NOT NULL", id).Take(&answer).Error
+		if err != nil {
+			return err
+		}
+
+		var question models.Question
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", answer.QuestionID).Take(&question).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrParentDeleted
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&answer).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&question).
+			UpdateColumns(map[string]interface{}{"answer_count": gorm.Expr("answer_count + 1"), "updated_at": now()}).Error
+	}))
+}
+
+func (r *trashRepository) Purge(ctx context.Context, before time.Time) (*models.PurgeResult, error) {
+	result := &models.PurgeResult{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var questionIDs []uint
+		err := tx.Unscoped().Model(&models.Question{}).Where("deleted_at < ?", before).Pluck("id", &questionIDs).Error
+		if err != nil {
+			return err
+		}
+		for _, id := range questionIDs {
+			if err := deleteQuestionDependents(tx, id); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("question_id = ?", id).Delete(&models.Answer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&models.Question{}, id).Error; err != nil {
+				return err
+			}
+		}
+		result.Questions = int64(len(questionIDs))
+
+		var answerIDs []uint
+		err = tx.Unscoped().Model(&models.Answer{}).Where("deleted_at < ?", before).Pluck("id", &answerIDs).Error
+		if err != nil || len(answerIDs) == 0 {
+			return err
+		}
+		if err := deleteDependents(tx, models.RevisionEntityAnswer, answerIDs); err != nil {
+			return err
+		}
+		deleted := tx.Unscoped().Delete(&models.Answer{}, answerIDs)
+		result.Answers = deleted.RowsAffected
+		return deleted.Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"qa-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type voteRepository struct {
+	db *gorm.DB
+}
+
+func NewVoteRepository(db *gorm.DB) VoteRepository {
+	return &voteRepository{db: db}
+}
+
+func (r *voteRepository) Cast(ctx context.Context, vote *models.Vote) (int, error) {
+	var target interface{} = &models.Question{}
+	if vote.TargetType == models.VoteTargetAnswer {
+		target = &models.Answer{}
+	}
+
+	var score int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the target serializes votes on it, so the score delta
+		// below is computed against the vote that is actually stored.
+		var current struct {
+			Score      int
+			QuestionID uint
+		}
+		columns := []string{"score"}
+		if vote.TargetType == models.VoteTargetAnswer {
+			columns = append(columns, "question_id")
+		}
+		if err := tx.Model(target).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select(columns).Where("id = ?", vote.TargetID).Take(&current).Error; err != nil {
+			return err
+		}
+
+		var existing models.Vote
+		err := tx.Where("user_id = ? AND target_type = ? AND target_id = ?", vote.UserID, vote.TargetType, vote.TargetID).
+			Take(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		switch {
+		case existing.ID != 0 && vote.Value == 0:
+			err = tx.Delete(&existing).Error
+		case existing.ID != 0:
+			vote.ID = existing.ID
+			vote.CreatedAt = existing.CreatedAt
+			err = tx.Model(&existing).Update("value", vote.Value).Error
+		case vote.Value != 0:
+			err = tx.Create(vote).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		score = current.Score + vote.Value - existing.Value
+		if score == current.Score {
+			return nil
+		}
+		updatedAt := now()
+		if err := tx.Model(target).Where("id = ?", vote.TargetID).
+			UpdateColumns(map[string]interface{}{"score": score, "updated_at": updatedAt}).Error; err != nil {
+			return err
+		}
+		if vote.TargetType == models.VoteTargetAnswer {
+			return touchQuestion(tx, current.QuestionID, updatedAt)
+		}
+		return nil
+	})
+	return score, translateError(err)
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/metrics"
+	"qa-service/internal/policy"
+	"qa-service/internal/problem"
+)
+
+var (
+	errInvalidCredentials     = apperr.Unauthorized("invalid_credentials", "invalid credentials")
+	errAuthenticationRequired = apperr.Unauthorized("authentication_required", "authentication required")
+)
+
+// authMiddleware attaches the caller's principal to the request context.
+// Reads may be anonymous; every other method needs valid credentials.
+// Invalid credentials are charged to the client address's rate limit
+// bucket, so that guessing tokens or API keys is throttled.
+func authMiddleware(authenticator *auth.Authenticator, rateLimit RateLimit, m *metrics.Metrics, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				logger.InfoContext(r.Context(), "authentication failed", "error", err)
+				if errors.Is(err, auth.ErrInvalidCredentials) {
+					if rateLimit.Limiter != nil && !rateLimit.take(w, r, clientKey(r, rateLimit.TrustedProxies), m, logger) {
+						return
+					}
+					unauthorized(w, r, errInvalidCredentials)
+				} else {
+					problem.Write(w, r, err)
+				}
+				return
+			}
+
+			if principal == nil {
+				if !isReadOnly(r.Method) {
+					unauthorized(w, r, errAuthenticationRequired)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func requirePermission(authz *policy.Policy, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := auth.FromContext(r.Context())
+			if principal == nil {
+				unauthorized(w, r, errAuthenticationRequired)
+				return
+			}
+			if err := authz.Authorize(principal, action, policy.Resource{}); err != nil {
+				problem.Write(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="qa-service"`)
+	problem.Write(w, r, err)
+}
+
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,171 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/idempotency"
+	"qa-service/internal/problem"
+	"qa-service/internal/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes retries of the create routes safe; a nil Store disables
+// it. Responses are kept for TTL; a request still running after LockTimeout
+// no longer blocks its retries.
+type Idempotency struct {
+	Store       idempotency.Store
+	TTL         time.Duration
+	LockTimeout time.Duration
+}
+
+var (
+	errInvalidIdempotencyKey = apperr.Validation("invalid_idempotency_key", "Idempotency-Key must be 1 to 255 printable ASCII characters")
+	errIdempotencyKeyInUse   = apperr.Conflict("idempotency_key_in_use", "a request with this idempotency key is still in progress")
+	errIdempotencyKeyReused  = apperr.Unprocessable("idempotency_key_reused", "idempotency key was already used for a different request")
+	errUnreadableBody        = apperr.Validation("invalid_body", "request body could not be read")
+)
+
+// idempotencyMiddleware answers a create request carrying an Idempotency-Key
+// the caller already used on the same route with the response to the first
+// request. Keys are scoped to the caller and route; reusing one with another
+// path or body is an error. Server errors are not stored, so the request can
+// be retried.
+func idempotencyMiddleware(cfg Idempotency, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			template, ok := routeTemplate(r)
+			principal := auth.FromContext(r.Context())
+			if key == "" || !ok || !createRoutes[r.Method+" "+template] || principal == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength || !printable(key) {
+				problem.Write(w, r, errInvalidIdempotencyKey)
+				return
+			}
+
+			// Oversized bodies are cut short here and rejected by the
+			// handler, which is as good as hashing all of them.
+			body, err := io.ReadAll(io.LimitReader(r.Body, validation.MaxBodyBytes+1))
+			if err != nil {
+				problem.Write(w, r, errUnreadableBody)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := idempotencyStoreKey(principal.Subject, r.Method, template, key)
+			sum := sha256.Sum256(append([]byte(r.URL.Path+"\n"), body...))
+			claim, stored, err := cfg.Store.Begin(r.Context(), storeKey, hex.EncodeToString(sum[:]), cfg.LockTimeout)
+			switch {
+			case errors.Is(err, idempotency.ErrInFlight):
+				problem.Write(w, r, errIdempotencyKeyInUse)
+				return
+			case errors.Is(err, idempotency.ErrMismatch):
+				problem.Write(w, r, errIdempotencyKeyReused)
+				return
+			case err != nil:
+				// Going ahead could create a duplicate, which is what the
+				// caller sent the key to avoid.
+				problem.Write(w, r, apperr.Unavailable(err))
+				return
+			case stored != nil:
+				replay(w, *stored)
+				return
+			}
+
+			rec := &bufferedResponse{ResponseWriter: w, header: make(http.Header)}
+			next.ServeHTTP(rec, r)
+			if !rec.wroteHeader {
+				rec.WriteHeader(http.StatusOK)
+			}
+
+			// The request context may be done by now, the outcome must
+			// still be recorded.
+			ctx := context.WithoutCancel(r.Context())
+			if rec.status >= http.StatusInternalServerError {
+				err = cfg.Store.Release(ctx, storeKey, claim)
+			} else {
+				err = cfg.Store.Complete(ctx, storeKey, claim, idempotency.Response{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}, cfg.TTL)
+			}
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to record idempotent response", "error", err)
+			}
+		})
+	}
+}
+
+// idempotencyStoreKey joins the parts with their lengths in front, so that
+// no two distinct sets of parts share a key whatever characters they hold.
+func idempotencyStoreKey(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(strconv.Itoa(len(part)))
+		b.WriteByte(':')
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+func replay(w http.ResponseWriter, resp idempotency.Response) {
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// bufferedResponse passes a response through while keeping a copy of it.
+// Handlers get headers of their own, so that the copy leaves out those set
+// by outer middleware, such as X-Request-ID.
+type bufferedResponse struct {
+	http.ResponseWriter
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+	b.header = b.header.Clone()
+	for name, values := range b.header {
+		b.ResponseWriter.Header()[name] = values
+	}
+	b.ResponseWriter.WriteHeader(status)
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if !b.wroteHeader {
+		b.WriteHeader(http.StatusOK)
+	}
+	b.body.Write(p)
+	return b.ResponseWriter.Write(p)
+}
+
+func (b *bufferedResponse) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+	"qa-service/internal/metrics"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing
+// random paths cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+func metricsMiddleware(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			route, ok := routeTemplate(r)
+			if !ok {
+				route = unmatchedRoute
+			}
+			m.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+		})
+	}
+}
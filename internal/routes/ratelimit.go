@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/metrics"
+	"qa-service/internal/problem"
+	"qa-service/internal/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit throttles API requests; a nil Limiter disables it. Requests from
+// TrustedProxies are attributed to the client they name in X-Forwarded-For.
+type RateLimit struct {
+	Limiter        *ratelimit.Limiter
+	TrustedProxies []netip.Prefix
+}
+
+var errRateLimited = apperr.RateLimited("too many requests, retry later")
+
+func rateLimitGroup(r *http.Request) string {
+	if isReadOnly(r.Method) {
+		return ratelimit.GroupRead
+	}
+	if template, ok := routeTemplate(r); ok && createRoutes[r.Method+" "+template] {
+		return ratelimit.GroupCreate
+	}
+	return ratelimit.GroupWrite
+}
+
+// rateLimitKey names the caller's bucket: the API key, else the user, else
+// for anonymous requests the client address.
+func rateLimitKey(r *http.Request, trustedProxies []netip.Prefix) string {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		if principal.Method == auth.MethodAPIKey {
+			return "key:" + strconv.FormatUint(uint64(principal.APIKeyID), 10)
+		}
+		return "user:" + principal.Subject
+	}
+	return clientKey(r, trustedProxies)
+}
+
+func clientKey(r *http.Request, trustedProxies []netip.Prefix) string {
+	if addr := clientIP(r, trustedProxies); addr.IsValid() {
+		return "ip:" + addr.String()
+	}
+	return "ip:" + r.RemoteAddr
+}
+
+// clientIP returns the address of the client. When the peer is a trusted
+// proxy, X-Forwarded-For is read from the right and the first address that
+// is not a trusted proxy is the client's; anything left of it may have been
+// made up by the client.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}
+	}
+	addr := addrPort.Addr().Unmap()
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(addr, trustedProxies); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+	}
+	return addr
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitMiddleware takes a token for every request and rejects it with
+// 429 when the caller's bucket is empty. It runs after authentication, so
+// that signed-in callers are limited per user rather than per address;
+// failed authentications are charged to the client address by
+// authMiddleware. If the store fails, requests are let through rather than
+// failing the API.
+func rateLimitMiddleware(rateLimit RateLimit, m *metrics.Metrics, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rateLimit.take(w, r, rateLimitKey(r, rateLimit.TrustedProxies), m, logger) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// take charges the request to the bucket named key and sets the rate limit
+// headers. When the bucket is empty it writes the 429 and returns false.
+func (rateLimit RateLimit) take(w http.ResponseWriter, r *http.Request, key string, m *metrics.Metrics, logger *slog.Logger) bool {
+	group := rateLimitGroup(r)
+	result, ok, err := rateLimit.Limiter.Take(r.Context(), group, key)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "rate limiter failed", "group", group, "error", err)
+		return true
+	}
+	if !ok {
+		return true
+	}
+
+	p := result.Policy
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(p.Burst))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", p.Limit, ceilSeconds(p.Period), p.Burst))
+	if !result.Allowed {
+		if m != nil {
+			m.RateLimited(group)
+		}
+		logger.DebugContext(r.Context(), "rate limited", "group", group)
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		problem.Write(w, r, errRateLimited)
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -1,47 +1,121 @@
 package routes
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"qa-service/internal/auth"
 	"qa-service/internal/handlers"
+	"qa-service/internal/metrics"
+	"qa-service/internal/policy"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes(questionHandler *handlers.QuestionHandler, answerHandler *handlers.AnswerHandler, logger *log.Logger) *mux.Router {
+type Handlers struct {
+	Questions *handlers.QuestionHandler
+	Answers   *handlers.AnswerHandler
+	Search    *handlers.SearchHandler
+	Votes     *handlers.VoteHandler
+	Tags      *handlers.TagHandler
+	APIKeys   *handlers.APIKeyHandler
+	Comments  *handlers.CommentHandler
+	Trash     *handlers.TrashHandler
+	Health    *handlers.HealthHandler
+}
+
+// createRoutes add content. They fall in the stricter create rate limit
+// group and honor Idempotency-Key.
+var createRoutes = map[string]bool{
+	"POST /api/v1/questions/":               true,
+	"POST /api/v1/questions/{id}/answers/":  true,
+	"POST /api/v1/questions/{id}/comments/": true,
+	"POST /api/v1/answers/{id}/comments/":   true,
+}
+
+// SetupRoutes builds the router. A nil m disables request metrics and the
+// /metrics endpoint.
+func SetupRoutes(h Handlers, authenticator *auth.Authenticator, authz *policy.Policy, timeouts Timeouts, rateLimit RateLimit, idempotency Idempotency, m *metrics.Metrics, logger *slog.Logger) *mux.Router {
 	router := mux.NewRouter()
 
-	router.Use(loggingMiddleware(logger))
+	observe := []mux.MiddlewareFunc{requestIDMiddleware, tracingMiddleware, accessLogMiddleware(logger)}
+	if m != nil {
+		observe = append(observe, metricsMiddleware(m))
+	}
+	router.Use(observe...)
+	router.Use(timeoutMiddleware(timeouts))
 
 	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(authMiddleware(authenticator, rateLimit, m, logger))
+	if rateLimit.Limiter != nil {
+		api.Use(rateLimitMiddleware(rateLimit, m, logger))
+	}
+	if idempotency.Store != nil {
+		api.Use(idempotencyMiddleware(idempotency, logger))
+	}
 
-	api.HandleFunc("/questions/", questionHandler.GetQuestions).Methods("GET")
-	api.HandleFunc("/questions/", questionHandler.CreateQuestion).Methods("POST")
-	api.HandleFunc("/questions/{id:[0-9]+}", questionHandler.GetQuestion).Methods("GET")
-	api.HandleFunc("/questions/{id:[0-9]+}", questionHandler.DeleteQuestion).Methods("DELETE")
+	api.HandleFunc("/questions/", h.Questions.GetQuestions).Methods("GET")
+	api.HandleFunc("/questions/", h.Questions.CreateQuestion).Methods("POST")
+	api.HandleFunc("/questions/{id:[0-9]+}", h.Questions.GetQuestion).Methods("GET")
+	api.HandleFunc("/questions/{id:[0-9]+}", h.Questions.UpdateQuestion).Methods("PATCH")
+	api.HandleFunc("/questions/{id:[0-9]+}", h.Questions.DeleteQuestion).Methods("DELETE")
+	api.HandleFunc("/questions/{id:[0-9]+}/revisions", h.Questions.GetQuestionRevisions).Methods("GET")
+	api.HandleFunc("/questions/{id:[0-9]+}/revisions/diff", h.Questions.DiffQuestionRevisions).Methods("GET")
+	api.HandleFunc("/questions/{id:[0-9]+}/revisions/{version:[0-9]+}/rollback", h.Questions.RollbackQuestion).Methods("POST")
+	api.HandleFunc("/questions/{id:[0-9]+}/vote", h.Votes.VoteQuestion).Methods("PUT")
+	api.HandleFunc("/questions/{id:[0-9]+}/accepted-answer", h.Questions.AcceptAnswer).Methods("PUT")
+	api.HandleFunc("/questions/{id:[0-9]+}/accepted-answer", h.Questions.UnacceptAnswer).Methods("DELETE")
+	api.HandleFunc("/questions/{id:[0-9]+}/comments/", h.Comments.GetQuestionComments).Methods("GET")
+	api.HandleFunc("/questions/{id:[0-9]+}/comments/", h.Comments.CreateQuestionComment).Methods("POST")
+	api.HandleFunc("/questions/{id:[0-9]+}/comments/{commentID:[0-9]+}", h.Comments.DeleteQuestionComment).Methods("DELETE")
 
-	api.HandleFunc("/questions/{id:[0-9]+}/answers/", answerHandler.CreateAnswer).Methods("POST")
-	api.HandleFunc("/answers/{id:[0-9]+}", answerHandler.GetAnswer).Methods("GET")
-	api.HandleFunc("/answers/{id:[0-9]+}", answerHandler.DeleteAnswer).Methods("DELETE")
+	api.HandleFunc("/questions/{id:[0-9]+}/answers/", h.Answers.GetAnswers).Methods("GET")
+	api.HandleFunc("/questions/{id:[0-9]+}/answers/", h.Answers.CreateAnswer).Methods("POST")
+	api.HandleFunc("/answers/{id:[0-9]+}", h.Answers.GetAnswer).Methods("GET")
+	api.HandleFunc("/answers/{id:[0-9]+}", h.Answers.UpdateAnswer).Methods("PATCH")
+	api.HandleFunc("/answers/{id:[0-9]+}", h.Answers.DeleteAnswer).Methods("DELETE")
+	api.HandleFunc("/answers/{id:[0-9]+}/revisions", h.Answers.GetAnswerRevisions).Methods("GET")
+	api.HandleFunc("/answers/{id:[0-9]+}/revisions/diff", h.Answers.DiffAnswerRevisions).Methods("GET")
+	api.HandleFunc("/answers/{id:[0-9]+}/revisions/{version:[0-9]+}/rollback", h.Answers.RollbackAnswer).Methods("POST")
+	api.HandleFunc("/answers/{id:[0-9]+}/vote", h.Votes.VoteAnswer).Methods("PUT")
+	api.HandleFunc("/answers/{id:[0-9]+}/comments/", h.Comments.GetAnswerComments).Methods("GET")
+	api.HandleFunc("/answers/{id:[0-9]+}/comments/", h.Comments.CreateAnswerComment).Methods("POST")
+	api.HandleFunc("/answers/{id:[0-9]+}/comments/{commentID:[0-9]+}", h.Comments.DeleteAnswerComment).Methods("DELETE")
 
-	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
+	api.HandleFunc("/search", h.Search.Search).Methods("GET")
+
+	api.HandleFunc("/tags", h.Tags.GetTags).Methods("GET")
+
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(requirePermission(authz, policy.ActionAdmin))
+	admin.HandleFunc("/tags/{name}", h.Tags.RenameTag).Methods("PUT")
+	admin.HandleFunc("/tags/{name}/merge", h.Tags.MergeTag).Methods("POST")
+	admin.HandleFunc("/api-keys", h.APIKeys.CreateAPIKey).Methods("POST")
+	admin.HandleFunc("/api-keys/{id:[0-9]+}", h.APIKeys.RevokeAPIKey).Methods("DELETE")
+	admin.HandleFunc("/trash", h.Trash.GetTrash).Methods("GET")
+	admin.HandleFunc("/trash/questions/{id:[0-9]+}/restore", h.Trash.RestoreQuestion).Methods("POST")
+	admin.HandleFunc("/trash/answers/{id:[0-9]+}/restore", h.Trash.RestoreAnswer).Methods("POST")
+
+	router.HandleFunc("/livez", h.Health.Livez).Methods("GET")
+	router.HandleFunc("/readyz", h.Health.Readyz).Methods("GET")
+	if m != nil {
+		router.Handle("/metrics", m.Handler()).Methods("GET")
+	}
+
+	// The router runs middleware only for matched routes, so the fallback
+	// handlers are wrapped to log and count unmatched requests as well.
+	router.NotFoundHandler = chain(http.NotFoundHandler(), observe)
+	router.MethodNotAllowedHandler = chain(http.HandlerFunc(methodNotAllowed), observe)
 
 	return router
 }
 
-func loggingMiddleware(logger *log.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger.Printf("%s %s", r.Method, r.URL.Path)
-			next.ServeHTTP(w, r)
-		})
+func chain(h http.Handler, middleware []mux.MiddlewareFunc) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
 	}
+	return h
 }
 
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(`{"status": "ok"}`)); err != nil {
-		log.Printf("Error writing health check response: %v", err)
-	}
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusMethodNotAllowed)
 }
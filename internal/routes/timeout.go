@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Timeouts bounds how long a request may keep working. Routes overrides
+// Default for single routes, keyed by method and path template such as
+// "GET /api/v1/questions/{id}"; a zero duration disables the deadline.
+type Timeouts struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+var templateVariable = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// routeTemplate returns the path template of the route r matched, with
+// variable patterns removed: "/api/v1/questions/{id}". ok is false for
+// requests that matched no route.
+func routeTemplate(r *http.Request) (template string, ok bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+	return templateVariable.ReplaceAllString(template, "{$1}"), true
+}
+
+func (t Timeouts) forRequest(r *http.Request) time.Duration {
+	template, ok := routeTemplate(r)
+	if !ok {
+		return t.Default
+	}
+	if d, ok := t.Routes[r.Method+" "+template]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// timeoutMiddleware gives each request a deadline. Repositories run their
+// queries with the request context, so a query still running at the
+// deadline is cancelled and the request fails with 504.
+func timeoutMiddleware(timeouts Timeouts) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := timeouts.forRequest(r)
+			if d <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,416 @@
+// Package schemacheck compares the schema the GORM models describe with the
+// schema of a live PostgreSQL database, so that drift between the models and
+// the SQL migrations is caught before it surfaces as a failing query.
+package schemacheck
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	KindMissingTable      = "missing_table"
+	KindMissingColumn     = "missing_column"
+	KindExtraColumn       = "extra_column"
+	KindTypeMismatch      = "type_mismatch"
+	KindMissingIndex      = "missing_index"
+	KindMissingForeignKey = "missing_foreign_key"
+	KindForeignKeyAction  = "foreign_key_action"
+)
+
+// Drift is one difference between the models and the database.
+type Drift struct {
+	Table   string
+	Kind    string
+	Message string
+}
+
+func (d Drift) String() string {
+	return d.Table + ": " + d.Message
+}
+
+type Column struct {
+	Name string
+	Type string
+	// Generated columns are computed by the database and never mapped by
+	// the models, so they do not count as extra.
+	Generated bool
+}
+
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+type ForeignKey struct {
+	Columns    []string
+	References string
+	OnDelete   string
+	OnUpdate   string
+}
+
+type Table struct {
+	Name        string
+	Columns     []Column
+	Indexes     []Index
+	ForeignKeys []ForeignKey
+}
+
+// Check compares the tables of models with the database behind db.
+func Check(ctx context.Context, db *gorm.DB, models ...interface{}) ([]Drift, error) {
+	expected, err := Expected(db, models...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(expected))
+	for i, table := range expected {
+		names[i] = table.Name
+	}
+	live, err := Inspect(ctx, db, names...)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(expected, live), nil
+}
+
+// Expected returns the tables models map to, including many2many join
+// tables, with column types as db's dialect would create them. Only the
+// indexes and foreign keys the models declare are included.
+func Expected(db *gorm.DB, models ...interface{}) ([]Table, error) {
+	tables := map[string]*Table{}
+	table := func(name string) *Table {
+		if tables[name] == nil {
+			tables[name] = &Table{Name: name}
+		}
+		return tables[name]
+	}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		sch := stmt.Schema
+
+		t := table(sch.Table)
+		t.Columns = columnsOf(db, sch)
+		for _, idx := range sch.ParseIndexes() {
+			index := Index{Name: idx.Name, Unique: idx.Class == "UNIQUE"}
+			for _, field := range idx.Fields {
+				index.Columns = append(index.Columns, field.DBName)
+			}
+			t.Indexes = append(t.Indexes, index)
+		}
+
+		for _, rel := range sch.Relationships.Relations {
+			rels := []*schema.Relationship{rel}
+			if rel.JoinTable != nil {
+				join := table(rel.JoinTable.Table)
+				join.Columns = columnsOf(db, rel.JoinTable)
+				rels = rels[:0]
+				for _, joinRel := range rel.JoinTable.Relationships.Relations {
+					rels = append(rels, joinRel)
+				}
+			}
+			for _, r := range rels {
+				constraint := r.ParseConstraint()
+				if constraint == nil {
+					continue
+				}
+				fk := ForeignKey{
+					References: constraint.ReferenceSchema.Table,
+					OnDelete:   foreignKeyAction(constraint.OnDelete),
+					OnUpdate:   foreignKeyAction(constraint.OnUpdate),
+				}
+				for _, field := range constraint.ForeignKeys {
+					fk.Columns = append(fk.Columns, field.DBName)
+				}
+				owner := table(constraint.Schema.Table)
+				if findForeignKey(owner.ForeignKeys, fk) == nil {
+					owner.ForeignKeys = append(owner.ForeignKeys, fk)
+				}
+			}
+		}
+	}
+
+	result := make([]Table, 0, len(tables))
+	for _, t := range tables {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func columnsOf(db *gorm.DB, sch *schema.Schema) []Column {
+	var columns []Column
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		columns = append(columns, Column{Name: field.DBName, Type: NormalizeType(db.Dialector.DataTypeOf(field))})
+	}
+	return columns
+}
+
+// Inspect reads the named tables from the current schema of the database.
+// Tables that do not exist are left out.
+func Inspect(ctx context.Context, db *gorm.DB, names ...string) ([]Table, error) {
+	db = db.WithContext(ctx)
+	var tables []Table
+	for _, name := range names {
+		table := Table{Name: name}
+
+		if err := db.Raw(`
+			SELECT a.attname AS name, format_type(a.atttypid, a.atttypmod) AS type, a.attgenerated = 's' AS generated
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = current_schema() AND c.relname = ? AND c.relkind IN ('r', 'p')
+				AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`, name).Scan(&table.Columns).Error; err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+		}
+		if len(table.Columns) == 0 {
+			continue
+		}
+		for i := range table.Columns {
+			table.Columns[i].Type = NormalizeType(table.Columns[i].Type)
+		}
+
+		var indexes []struct {
+			Name     string
+			Columns  string
+			IsUnique bool
+		}
+		if err := db.Raw(`
+			SELECT i.relname AS name, ix.indisunique AS is_unique,
+				array_to_string(ARRAY(
+					SELECT a.attname
+					FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+					ORDER BY k.ord), ',') AS columns
+			FROM pg_index ix
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			WHERE n.nspname = current_schema() AND t.relname = ?`, name).Scan(&indexes).Error; err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", name, err)
+		}
+		for _, idx := range indexes {
+			table.Indexes = append(table.Indexes, Index{Name: idx.Name, Columns: splitColumns(idx.Columns), Unique: idx.IsUnique})
+		}
+
+		var foreignKeys []struct {
+			Columns    string
+			References string
+			OnDelete   string
+			OnUpdate   string
+		}
+		if err := db.Raw(`
+			SELECT r.relname AS "references", c.confdeltype::text AS on_delete, c.confupdtype::text AS on_update,
+				array_to_string(ARRAY(
+					SELECT a.attname
+					FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+					JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+					ORDER BY k.ord), ',') AS columns
+			FROM pg_constraint c
+			JOIN pg_class t ON t.oid = c.conrelid
+			JOIN pg_class r ON r.oid = c.confrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			WHERE n.nspname = current_schema() AND t.relname = ? AND c.contype = 'f'`, name).Scan(&foreignKeys).Error; err != nil {
+			return nil, fmt.Errorf("failed to read foreign keys of %s: %w", name, err)
+		}
+		for _, fk := range foreignKeys {
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+				Columns:    splitColumns(fk.Columns),
+				References: fk.References,
+				OnDelete:   pgForeignKeyActions[fk.OnDelete],
+				OnUpdate:   pgForeignKeyActions[fk.OnUpdate],
+			})
+		}
+
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// Compare reports how live differs from expected. Indexes and foreign keys
+// that exist only in the database are not drift: migrations may add indexes
+// the models cannot express, such as GIN or descending ones.
+func Compare(expected, live []Table) []Drift {
+	liveTables := make(map[string]Table, len(live))
+	for _, table := range live {
+		liveTables[table.Name] = table
+	}
+
+	var drifts []Drift
+	for _, want := range expected {
+		report := func(kind, format string, args ...interface{}) {
+			drifts = append(drifts, Drift{Table: want.Name, Kind: kind, Message: fmt.Sprintf(format, args...)})
+		}
+
+		got, ok := liveTables[want.Name]
+		if !ok {
+			report(KindMissingTable, "table is missing")
+			continue
+		}
+
+		gotColumns := make(map[string]Column, len(got.Columns))
+		for _, column := range got.Columns {
+			gotColumns[column.Name] = column
+		}
+		wantColumns := make(map[string]bool, len(want.Columns))
+		for _, column := range want.Columns {
+			wantColumns[column.Name] = true
+			actual, ok := gotColumns[column.Name]
+			switch {
+			case !ok:
+				report(KindMissingColumn, "column %s %s is missing", column.Name, column.Type)
+			case actual.Type != column.Type:
+				report(KindTypeMismatch, "column %s is %s, models expect %s", column.Name, actual.Type, column.Type)
+			}
+		}
+		for _, column := range got.Columns {
+			if !wantColumns[column.Name] && !column.Generated {
+				report(KindExtraColumn, "column %s %s is not mapped by any model", column.Name, column.Type)
+			}
+		}
+
+		for _, index := range want.Indexes {
+			if !hasIndex(got.Indexes, index) {
+				kind := "index"
+				if index.Unique {
+					kind = "unique index"
+				}
+				report(KindMissingIndex, "%s %s on (%s) is missing", kind, index.Name, strings.Join(index.Columns, ", "))
+			}
+		}
+
+		for _, fk := range want.ForeignKeys {
+			actual := findForeignKey(got.ForeignKeys, fk)
+			switch {
+			case actual == nil:
+				report(KindMissingForeignKey, "foreign key (%s) to %s is missing", strings.Join(fk.Columns, ", "), fk.References)
+			case actual.OnDelete != fk.OnDelete || actual.OnUpdate != fk.OnUpdate:
+				report(KindForeignKeyAction, "foreign key (%s) to %s is ON DELETE %s ON UPDATE %s, models expect ON DELETE %s ON UPDATE %s",
+					strings.Join(fk.Columns, ", "), fk.References, actual.OnDelete, actual.OnUpdate, fk.OnDelete, fk.OnUpdate)
+			}
+		}
+	}
+	return drifts
+}
+
+// hasIndex reports whether an index over the same columns exists. A unique
+// index also satisfies a plain one.
+func hasIndex(indexes []Index, want Index) bool {
+	for _, index := range indexes {
+		if sameColumns(index.Columns, want.Columns) && (index.Unique || !want.Unique) {
+			return true
+		}
+	}
+	return false
+}
+
+func findForeignKey(foreignKeys []ForeignKey, want ForeignKey) *ForeignKey {
+	for i, fk := range foreignKeys {
+		if fk.References == want.References && sameColumns(fk.Columns, want.Columns) {
+			return &foreignKeys[i]
+		}
+	}
+	return nil
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitColumns(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+var pgForeignKeyActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+func foreignKeyAction(action string) string {
+	if action == "" {
+		return "NO ACTION"
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(action), " "))
+}
+
+var typeArgs = regexp.MustCompile(`^([a-z0-9 ]+?)\s*(\(.*\))?$`)
+
+var typeAliases = map[string]string{
+	"serial":      "integer",
+	"serial4":     "integer",
+	"int":         "integer",
+	"int4":        "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"int8":        "bigint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"int2":        "smallint",
+	"bool":        "boolean",
+	"varchar":     "character varying",
+	"char":        "character",
+	"decimal":     "numeric",
+	"float8":      "double precision",
+	"float4":      "real",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// NormalizeType spells a PostgreSQL type the way format_type does, so that
+// "varchar(255)" and "character varying(255)" or "serial" and "integer"
+// compare equal.
+func NormalizeType(t string) string {
+	t = strings.Join(strings.Fields(strings.ToLower(t)), " ")
+	m := typeArgs.FindStringSubmatch(t)
+	if m == nil {
+		return t
+	}
+	name, args := m[1], strings.ReplaceAll(m[2], " ", "")
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	switch name {
+	case "character":
+		if args == "" {
+			args = "(1)"
+		}
+	case "timestamp without time zone", "timestamp with time zone", "time without time zone", "time with time zone":
+		// format_type puts the precision after the type name:
+		// timestamp(3) with time zone.
+		if args != "" {
+			base, zone, _ := strings.Cut(name, " ")
+			return base + args + " " + zone
+		}
+	}
+	return name + args
+}
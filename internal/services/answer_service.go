@@ -1,65 +1,228 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/metrics"
 	"qa-service/internal/models"
+	"qa-service/internal/policy"
 	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
 )
 
 type AnswerService struct {
-	answerRepo   *repository.AnswerRepository
-	questionRepo *repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	questionRepo repository.QuestionRepository
+	revisionRepo repository.RevisionRepository
+	authz        *policy.Policy
+	metrics      *metrics.Metrics
 }
 
-func NewAnswerService(answerRepo *repository.AnswerRepository, questionRepo *repository.QuestionRepository) *AnswerService {
+func NewAnswerService(answerRepo repository.AnswerRepository, questionRepo repository.QuestionRepository, revisionRepo repository.RevisionRepository, authz *policy.Policy, m *metrics.Metrics) *AnswerService {
 	return &AnswerService{
 		answerRepo:   answerRepo,
 		questionRepo: questionRepo,
+		revisionRepo: revisionRepo,
+		authz:        authz,
+		metrics:      m,
 	}
 }
 
-func (s *AnswerService) CreateAnswer(questionID uint, req *models.CreateAnswerRequest) (*models.Answer, error) {
-	if req.Text == "" {
-		return nil, errors.New("answer text cannot be empty")
+func (s *AnswerService) CreateAnswer(ctx context.Context, principal *auth.Principal, questionID uint, req *models.CreateAnswerRequest) (*models.Answer, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.CreateAnswer")
+	defer span.End()
+
+	userID, err := authorID(principal)
+	if err != nil {
+		return nil, err
 	}
-	if req.UserID == "" {
-		return nil, errors.New("user ID cannot be empty")
+	if req.Text == "" {
+		return nil, apperr.Validation("answer_text_empty", "answer text cannot be empty")
 	}
 
-	exists, err := s.questionRepo.Exists(questionID)
+	exists, err := s.questionRepo.Exists(ctx, questionID)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.New("question not found")
+		return nil, errQuestionNotFound
 	}
 
 	answer := &models.Answer{
 		QuestionID: questionID,
-		UserID:     req.UserID,
+		UserID:     userID,
 		Text:       req.Text,
 	}
 
-	err = s.answerRepo.Create(answer)
+	err = s.answerRepo.Create(ctx, answer)
 	if err != nil {
 		return nil, err
 	}
+	s.metrics.AnswerCreated()
 
 	return answer, nil
 }
 
-func (s *AnswerService) GetAnswerByID(id uint) (*models.Answer, error) {
-	return s.answerRepo.GetByID(id)
+func (s *AnswerService) ListAnswers(ctx context.Context, questionID uint, opts models.AnswerListOptions) (*models.AnswerPage, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.ListAnswers")
+	defer span.End()
+
+	exists, err := s.questionRepo.Exists(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errQuestionNotFound
+	}
+
+	return s.answerRepo.ListByQuestionID(ctx, questionID, normalizeAnswerListOptions(opts))
+}
+
+func (s *AnswerService) GetAnswerByID(ctx context.Context, id uint) (*models.Answer, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.GetAnswerByID")
+	defer span.End()
+
+	return s.getAnswer(ctx, id)
 }
 
-func (s *AnswerService) DeleteAnswer(id uint) error {
-	exists, err := s.answerRepo.Exists(id)
+func (s *AnswerService) UpdateAnswer(ctx context.Context, principal *auth.Principal, id uint, req *models.UpdateAnswerRequest, cond *Precondition) (*models.Answer, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.UpdateAnswer")
+	defer span.End()
+
+	editorID, err := authorID(principal)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if req.Text == "" {
+		return nil, apperr.Validation("answer_text_empty", "answer text cannot be empty")
+	}
+
+	answer, err := s.getAnswer(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionEditAnswer, answerResource(answer)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(answer.Version); err != nil {
+		return nil, err
+	}
+
+	return s.editAnswer(ctx, answer, req.Text, editorID, cond)
+}
+
+func (s *AnswerService) ListAnswerRevisions(ctx context.Context, id uint) ([]models.Revision, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.ListAnswerRevisions")
+	defer span.End()
+
+	exists, err := s.answerRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 	if !exists {
-		return errors.New("answer not found")
+		return nil, errAnswerNotFound
+	}
+
+	return s.revisionRepo.ListByEntity(ctx, models.RevisionEntityAnswer, id)
+}
+
+func (s *AnswerService) DiffAnswerRevisions(ctx context.Context, id uint, from, to int) (*models.RevisionDiff, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.DiffAnswerRevisions")
+	defer span.End()
+
+	answer, err := s.getAnswer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffVersions(ctx, s.revisionRepo, models.RevisionEntityAnswer, id, answer.Version, answer.Text, from, to)
+}
+
+func (s *AnswerService) RollbackAnswer(ctx context.Context, principal *auth.Principal, id uint, version int, cond *Precondition) (*models.Answer, error) {
+	ctx, span := tracing.Start(ctx, "AnswerService.RollbackAnswer")
+	defer span.End()
+
+	editorID, err := authorID(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := s.getAnswer(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionEditAnswer, answerResource(answer)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(answer.Version); err != nil {
+		return nil, err
+	}
+
+	text, err := textAtVersion(ctx, s.revisionRepo, models.RevisionEntityAnswer, id, answer.Version, answer.Text, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editAnswer(ctx, answer, text, editorID, cond)
+}
+
+func (s *AnswerService) getAnswer(ctx context.Context, id uint) (*models.Answer, error) {
+	answer, err := s.answerRepo.GetByID(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, errAnswerNotFound
+	}
+	return answer, err
+}
+
+func (s *AnswerService) editAnswer(ctx context.Context, answer *models.Answer, text, editorID string, cond *Precondition) (*models.Answer, error) {
+	if answer.Text == text {
+		return answer, nil
 	}
 
-	return s.answerRepo.Delete(id)
+	revision := &models.Revision{
+		EntityType: models.RevisionEntityAnswer,
+		EntityID:   answer.ID,
+		Version:    answer.Version,
+		EditorID:   editorID,
+		Text:       answer.Text,
+	}
+	answer.Text = text
+
+	err := cond.failed(s.answerRepo.Update(ctx, answer, revision))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, apperr.Conflict("answer_edit_conflict", "answer was modified concurrently")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func (s *AnswerService) DeleteAnswer(ctx context.Context, principal *auth.Principal, id uint, cond *Precondition) error {
+	ctx, span := tracing.Start(ctx, "AnswerService.DeleteAnswer")
+	defer span.End()
+
+	if _, err := authorID(principal); err != nil {
+		return err
+	}
+
+	answer, err := s.getAnswer(ctx, id)
+	if err != nil {
+		return cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionDeleteAnswer, answerResource(answer)); err != nil {
+		return err
+	}
+	if err := cond.check(answer.Version); err != nil {
+		return err
+	}
+
+	if err := s.answerRepo.Delete(ctx, id, cond.version(answer.Version)); err != nil {
+		return cond.failed(err)
+	}
+	s.metrics.AnswerDeleted()
+	return nil
 }
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+	"slices"
+	"strings"
+)
+
+var knownRoles = []string{auth.RoleUser, auth.RoleModerator, auth.RoleAdmin}
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer span.End()
+
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		return nil, apperr.Validation("user_id_empty", "user ID cannot be empty")
+	}
+
+	roles := models.RoleList{}
+	for _, role := range req.Roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !slices.Contains(knownRoles, role) {
+			return nil, apperr.Validation("unknown_role", fmt.Sprintf("unknown role %q", role))
+		}
+		if !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, auth.RoleUser)
+	}
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		KeyHash: auth.HashAPIKey(key),
+		Roles:   roles,
+	}
+	if err := s.apiKeyRepo.Create(ctx, &apiKey); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer span.End()
+
+	err := s.apiKeyRepo.Revoke(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return apperr.NotFound("api_key_not_found", "API key not found")
+	}
+	return err
+}
+
+// LookupAPIKey implements auth.APIKeyLookup.
+func (s *APIKeyService) LookupAPIKey(ctx context.Context, hash string) (*auth.Principal, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.LookupAPIKey")
+	defer span.End()
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hash)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Principal{
+		Subject:  key.UserID,
+		Roles:    key.Roles,
+		Method:   auth.MethodAPIKey,
+		APIKeyID: key.ID,
+	}, nil
+}
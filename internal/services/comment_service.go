@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/models"
+	"qa-service/internal/policy"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+	"strings"
+	"unicode/utf8"
+)
+
+type CommentService struct {
+	commentRepo  repository.CommentRepository
+	questionRepo repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	authz        *policy.Policy
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, questionRepo repository.QuestionRepository, answerRepo repository.AnswerRepository, authz *policy.Policy) *CommentService {
+	return &CommentService{
+		commentRepo:  commentRepo,
+		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		authz:        authz,
+	}
+}
+
+func (s *CommentService) CreateComment(ctx context.Context, principal *auth.Principal, targetType string, targetID uint, req *models.CreateCommentRequest) (*models.Comment, error) {
+	ctx, span := tracing.Start(ctx, "CommentService.CreateComment")
+	defer span.End()
+
+	userID, err := authorID(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, apperr.Validation("comment_text_empty", "comment text cannot be empty")
+	}
+	if utf8.RuneCountInString(text) > models.CommentMaxLength {
+		return nil, apperr.Validation("comment_text_too_long", fmt.Sprintf("comment text cannot be longer than %d characters", models.CommentMaxLength))
+	}
+
+	comment := &models.Comment{
+		TargetType: targetType,
+		TargetID:   targetID,
+		UserID:     userID,
+		Text:       text,
+	}
+
+	err = s.commentRepo.Create(ctx, comment)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, targetNotFound(targetType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func (s *CommentService) ListComments(ctx context.Context, targetType string, targetID uint) ([]models.Comment, error) {
+	ctx, span := tracing.Start(ctx, "CommentService.ListComments")
+	defer span.End()
+
+	exists, err := s.targetExists(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, targetNotFound(targetType)
+	}
+
+	return s.commentRepo.ListByTarget(ctx, targetType, targetID)
+}
+
+func (s *CommentService) DeleteComment(ctx context.Context, principal *auth.Principal, targetType string, targetID, id uint) error {
+	ctx, span := tracing.Start(ctx, "CommentService.DeleteComment")
+	defer span.End()
+
+	if _, err := authorID(principal); err != nil {
+		return err
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if comment.TargetType != targetType || comment.TargetID != targetID {
+		return errCommentNotFound
+	}
+	if err := s.authz.Authorize(principal, policy.ActionDeleteComment, policy.Resource{OwnerID: comment.UserID}); err != nil {
+		return err
+	}
+
+	err = s.commentRepo.Delete(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errCommentNotFound
+	}
+	return err
+}
+
+func (s *CommentService) targetExists(ctx context.Context, targetType string, targetID uint) (bool, error) {
+	if targetType == models.CommentTargetAnswer {
+		return s.answerRepo.Exists(ctx, targetID)
+	}
+	return s.questionRepo.Exists(ctx, targetID)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+)
+
+var (
+	errAuthenticationRequired = apperr.Unauthorized("authentication_required", "authentication required")
+
+	errQuestionNotFound = apperr.NotFound("question_not_found", "question not found")
+	errAnswerNotFound   = apperr.NotFound("answer_not_found", "answer not found")
+	errRevisionNotFound = apperr.NotFound("revision_not_found", "revision not found")
+	errCommentNotFound  = apperr.NotFound("comment_not_found", "comment not found")
+	errTagNotFound      = apperr.NotFound("tag_not_found", "tag not found")
+)
+
+// targetNotFound is the not-found error for a vote or comment target.
+func targetNotFound(targetType string) error {
+	if targetType == models.VoteTargetAnswer {
+		return errAnswerNotFound
+	}
+	return errQuestionNotFound
+}
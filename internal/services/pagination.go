@@ -0,0 +1,26 @@
+package services
+
+import "qa-service/internal/models"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+func clampPageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
+
+func normalizeAnswerListOptions(opts models.AnswerListOptions) models.AnswerListOptions {
+	opts.Limit = clampPageSize(opts.Limit)
+	if opts.Order == "" {
+		opts.Order = models.AnswerOrderOldest
+	}
+	return opts
+}
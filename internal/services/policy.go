@@ -0,0 +1,14 @@
+package services
+
+import (
+	"qa-service/internal/models"
+	"qa-service/internal/policy"
+)
+
+func questionResource(question *models.Question) policy.Resource {
+	return policy.Resource{OwnerID: question.UserID, AnswerCount: question.AnswerCount}
+}
+
+func answerResource(answer *models.Answer) policy.Resource {
+	return policy.Resource{OwnerID: answer.UserID}
+}
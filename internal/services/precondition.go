@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/repository"
+	"slices"
+)
+
+var errPreconditionFailed = apperr.PreconditionFailed("resource was modified since it was read, fetch it again")
+
+// Precondition holds the entity versions an If-Match header names. Requests
+// without the header pass a nil Precondition, which checks nothing.
+type Precondition struct {
+	// Any stands for If-Match: *, which only requires the entity to exist.
+	Any      bool
+	Versions []int
+}
+
+// check fails unless the entity's current version is one p names.
+func (p *Precondition) check(version int) error {
+	if p == nil || p.Any || slices.Contains(p.Versions, version) {
+		return nil
+	}
+	return errPreconditionFailed
+}
+
+// version is the version a guarded write has to find, so that the check and
+// the write happen in one statement. It is nil when p does not name one.
+func (p *Precondition) version(current int) *int {
+	if p == nil || p.Any {
+		return nil
+	}
+	return &current
+}
+
+// failed turns err into a failed precondition when the request carried
+// If-Match and err means the entity is gone or was changed under it.
+func (p *Precondition) failed(err error) error {
+	if p == nil {
+		return err
+	}
+	if errors.Is(err, apperr.ErrNotFound) || !p.Any && errors.Is(err, repository.ErrVersionConflict) {
+		return errPreconditionFailed
+	}
+	return err
+}
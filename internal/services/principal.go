@@ -0,0 +1,12 @@
+package services
+
+import "qa-service/internal/auth"
+
+// authorID returns the user the principal acts as; edits and new content are
+// always attributed to the authenticated caller.
+func authorID(principal *auth.Principal) (string, error) {
+	if principal == nil || principal.Subject == "" {
+		return "", errAuthenticationRequired
+	}
+	return principal.Subject, nil
+}
@@ -1,54 +1,348 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/metrics"
 	"qa-service/internal/models"
+	"qa-service/internal/policy"
 	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+	"slices"
 )
 
 type QuestionService struct {
-	questionRepo *repository.QuestionRepository
+	questionRepo repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	revisionRepo repository.RevisionRepository
+	authz        *policy.Policy
+	metrics      *metrics.Metrics
 }
 
-func NewQuestionService(questionRepo *repository.QuestionRepository) *QuestionService {
+func NewQuestionService(questionRepo repository.QuestionRepository, answerRepo repository.AnswerRepository, revisionRepo repository.RevisionRepository, authz *policy.Policy, m *metrics.Metrics) *QuestionService {
 	return &QuestionService{
 		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		revisionRepo: revisionRepo,
+		authz:        authz,
+		metrics:      m,
 	}
 }
 
-func (s *QuestionService) CreateQuestion(req *models.CreateQuestionRequest) (*models.Question, error) {
+func (s *QuestionService) CreateQuestion(ctx context.Context, principal *auth.Principal, req *models.CreateQuestionRequest) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.CreateQuestion")
+	defer span.End()
+
+	userID, err := authorID(principal)
+	if err != nil {
+		return nil, err
+	}
 	if req.Text == "" {
-		return nil, errors.New("question text cannot be empty")
+		return nil, apperr.Validation("question_text_empty", "question text cannot be empty")
+	}
+
+	tags, err := normalizeQuestionTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	question := &models.Question{
-		Text: req.Text,
+		UserID: userID,
+		Text:   req.Text,
+	}
+	for _, name := range tags {
+		question.Tags = append(question.Tags, models.Tag{Name: name})
 	}
 
-	err := s.questionRepo.Create(question)
+	err = s.questionRepo.Create(ctx, question)
 	if err != nil {
 		return nil, err
 	}
+	s.metrics.QuestionCreated()
 
 	return question, nil
 }
 
-func (s *QuestionService) GetAllQuestions() ([]models.Question, error) {
-	return s.questionRepo.GetAll()
+func (s *QuestionService) ListQuestions(ctx context.Context, opts models.QuestionListOptions) (*models.QuestionPage, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.ListQuestions")
+	defer span.End()
+
+	opts.Limit = clampPageSize(opts.Limit)
+	if opts.Sort == "" {
+		opts.Sort = models.QuestionSortCreatedAt
+	}
+	if opts.Order == "" {
+		opts.Order = models.SortDesc
+	}
+	if opts.TagMode == "" {
+		opts.TagMode = models.TagModeAll
+	}
+
+	tags, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+	opts.Tags = tags
+
+	return s.questionRepo.List(ctx, opts)
 }
 
-func (s *QuestionService) GetQuestionByID(id uint) (*models.Question, error) {
-	return s.questionRepo.GetByID(id)
+func (s *QuestionService) GetQuestionByID(ctx context.Context, id uint, answerOpts models.AnswerListOptions) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.GetQuestionByID")
+	defer span.End()
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := s.answerRepo.ListByQuestionID(ctx, id, normalizeAnswerListOptions(answerOpts))
+	if err != nil {
+		return nil, err
+	}
+	question.Answers = answers.Items
+	question.AnswersNextCursor = answers.NextCursor
+
+	return question, nil
 }
 
-func (s *QuestionService) DeleteQuestion(id uint) error {
-	exists, err := s.questionRepo.Exists(id)
+func (s *QuestionService) UpdateQuestion(ctx context.Context, principal *auth.Principal, id uint, req *models.UpdateQuestionRequest, cond *Precondition) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.UpdateQuestion")
+	defer span.End()
+
+	editorID, err := authorID(principal)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if req.Text == nil && req.Tags == nil {
+		return nil, apperr.Validation("nothing_to_update", "nothing to update")
+	}
+	if req.Text != nil && *req.Text == "" {
+		return nil, apperr.Validation("question_text_empty", "question text cannot be empty")
+	}
+
+	// tags stays nil when the request leaves them alone.
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeQuestionTags(*req.Tags); err != nil {
+			return nil, err
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionEditQuestion, questionResource(question)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(question.Version); err != nil {
+		return nil, err
+	}
+
+	text := question.Text
+	if req.Text != nil {
+		text = *req.Text
+	}
+	return s.editQuestion(ctx, question, text, tags, editorID, cond)
+}
+
+func (s *QuestionService) ListQuestionRevisions(ctx context.Context, id uint) ([]models.Revision, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.ListQuestionRevisions")
+	defer span.End()
+
+	exists, err := s.questionRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 	if !exists {
-		return errors.New("question not found")
+		return nil, errQuestionNotFound
+	}
+
+	return s.revisionRepo.ListByEntity(ctx, models.RevisionEntityQuestion, id)
+}
+
+func (s *QuestionService) DiffQuestionRevisions(ctx context.Context, id uint, from, to int) (*models.RevisionDiff, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.DiffQuestionRevisions")
+	defer span.End()
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffVersions(ctx, s.revisionRepo, models.RevisionEntityQuestion, id, question.Version, question.Text, from, to)
+}
+
+func (s *QuestionService) RollbackQuestion(ctx context.Context, principal *auth.Principal, id uint, version int, cond *Precondition) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.RollbackQuestion")
+	defer span.End()
+
+	editorID, err := authorID(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionEditQuestion, questionResource(question)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(question.Version); err != nil {
+		return nil, err
+	}
+
+	text, err := textAtVersion(ctx, s.revisionRepo, models.RevisionEntityQuestion, id, question.Version, question.Text, version)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.questionRepo.Delete(id)
+	return s.editQuestion(ctx, question, text, nil, editorID, cond)
+}
+
+func (s *QuestionService) AcceptAnswer(ctx context.Context, principal *auth.Principal, id uint, req *models.AcceptAnswerRequest, cond *Precondition) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.AcceptAnswer")
+	defer span.End()
+
+	if _, err := authorID(principal); err != nil {
+		return nil, err
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionAcceptAnswer, questionResource(question)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(question.Version); err != nil {
+		return nil, err
+	}
+
+	answer, err := s.answerRepo.GetByID(ctx, req.AnswerID)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, errAnswerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if answer.QuestionID != id {
+		return nil, apperr.Validation("answer_not_in_question", "answer does not belong to this question")
+	}
+
+	err = s.questionRepo.SetAcceptedAnswer(ctx, question, &answer.ID, cond.version(question.Version))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, errPreconditionFailed
+	}
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, errAnswerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func (s *QuestionService) UnacceptAnswer(ctx context.Context, principal *auth.Principal, id uint, cond *Precondition) (*models.Question, error) {
+	ctx, span := tracing.Start(ctx, "QuestionService.UnacceptAnswer")
+	defer span.End()
+
+	if _, err := authorID(principal); err != nil {
+		return nil, err
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionAcceptAnswer, questionResource(question)); err != nil {
+		return nil, err
+	}
+	if err := cond.check(question.Version); err != nil {
+		return nil, err
+	}
+
+	err = s.questionRepo.SetAcceptedAnswer(ctx, question, nil, cond.version(question.Version))
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, cond.failed(errQuestionNotFound)
+	}
+	if err != nil {
+		return nil, cond.failed(err)
+	}
+
+	return question, nil
+}
+
+func (s *QuestionService) getQuestion(ctx context.Context, id uint) (*models.Question, error) {
+	question, err := s.questionRepo.GetByID(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, errQuestionNotFound
+	}
+	return question, err
+}
+
+// editQuestion saves a new version with text and, unless tags is nil, with
+// tags replacing the current ones. Nothing is saved when neither changes.
+// The save only goes through at the version the question was read at,
+// which cond has already checked.
+func (s *QuestionService) editQuestion(ctx context.Context, question *models.Question, text string, tags []string, editorID string, cond *Precondition) (*models.Question, error) {
+	if tags != nil && slices.Equal(tags, tagNames(question.Tags)) {
+		tags = nil
+	}
+	if question.Text == text && tags == nil {
+		return question, nil
+	}
+
+	revision := &models.Revision{
+		EntityType: models.RevisionEntityQuestion,
+		EntityID:   question.ID,
+		Version:    question.Version,
+		EditorID:   editorID,
+		Text:       question.Text,
+	}
+	question.Text = text
+
+	err := cond.failed(s.questionRepo.Update(ctx, question, tags, revision))
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, apperr.Conflict("question_edit_conflict", "question was modified concurrently")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func (s *QuestionService) DeleteQuestion(ctx context.Context, principal *auth.Principal, id uint, cond *Precondition) error {
+	ctx, span := tracing.Start(ctx, "QuestionService.DeleteQuestion")
+	defer span.End()
+
+	if _, err := authorID(principal); err != nil {
+		return err
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return cond.failed(err)
+	}
+	if err := s.authz.Authorize(principal, policy.ActionDeleteQuestion, questionResource(question)); err != nil {
+		return err
+	}
+	if err := cond.check(question.Version); err != nil {
+		return err
+	}
+
+	if err := s.questionRepo.Delete(ctx, id, cond.version(question.Version)); err != nil {
+		return cond.failed(err)
+	}
+	s.metrics.QuestionDeleted()
+	return nil
 }
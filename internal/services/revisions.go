@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/diff"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+)
+
+func textAtVersion(ctx context.Context, revisionRepo repository.RevisionRepository, entityType string, entityID uint, currentVersion int, currentText string, version int) (string, error) {
+	if version == currentVersion {
+		return currentText, nil
+	}
+	if version < 1 || version > currentVersion {
+		return "", errRevisionNotFound
+	}
+
+	revision, err := revisionRepo.GetByVersion(ctx, entityType, entityID, version)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return "", errRevisionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return revision.Text, nil
+}
+
+func diffVersions(ctx context.Context, revisionRepo repository.RevisionRepository, entityType string, entityID uint, currentVersion int, currentText string, from, to int) (*models.RevisionDiff, error) {
+	fromText, err := textAtVersion(ctx, revisionRepo, entityType, entityID, currentVersion, currentText, from)
+	if err != nil {
+		return nil, err
+	}
+	toText, err := textAtVersion(ctx, revisionRepo, entityType, entityID, currentVersion, currentText, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RevisionDiff{
+		From:    from,
+		To:      to,
+		Changes: diff.Words(fromText, toText),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+	"strings"
+)
+
+type SearchService struct {
+	searchRepo repository.SearchRepository
+}
+
+func NewSearchService(searchRepo repository.SearchRepository) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+func (s *SearchService) Search(ctx context.Context, opts models.SearchOptions) ([]models.SearchResult, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.Search")
+	defer span.End()
+
+	opts.Query = strings.TrimSpace(opts.Query)
+	if opts.Query == "" {
+		return nil, apperr.Validation("search_query_empty", "search query cannot be empty")
+	}
+	opts.Limit = clampPageSize(opts.Limit)
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+
+	return s.searchRepo.Search(ctx, opts)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+)
+
+type TagService struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+	}
+}
+
+func (s *TagService) ListTags(ctx context.Context) ([]models.TagUsage, error) {
+	ctx, span := tracing.Start(ctx, "TagService.ListTags")
+	defer span.End()
+
+	return s.tagRepo.ListUsage(ctx)
+}
+
+func (s *TagService) RenameTag(ctx context.Context, name string, req *models.RenameTagRequest) error {
+	ctx, span := tracing.Start(ctx, "TagService.RenameTag")
+	defer span.End()
+
+	newName, err := normalizeTag(req.Name)
+	if err != nil {
+		return err
+	}
+	if newName == name {
+		return nil
+	}
+
+	err = s.tagRepo.Rename(ctx, name, newName)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errTagNotFound
+	}
+	if errors.Is(err, repository.ErrTagExists) {
+		return apperr.Conflict("tag_exists", "tag already exists, merge it instead")
+	}
+	return err
+}
+
+func (s *TagService) MergeTag(ctx context.Context, name string, req *models.MergeTagRequest) error {
+	ctx, span := tracing.Start(ctx, "TagService.MergeTag")
+	defer span.End()
+
+	into, err := normalizeTag(req.Into)
+	if err != nil {
+		return err
+	}
+	if into == name {
+		return apperr.Validation("tag_merge_into_itself", "cannot merge a tag into itself")
+	}
+
+	err = s.tagRepo.Merge(ctx, name, into)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errTagNotFound
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxTagsPerQuestion = 5
+	maxTagLength       = 35
+)
+
+// normalizeTag lowercases a tag and joins its words with dashes, so that
+// "Go Modules" and "go_modules" end up as the same "go-modules" tag.
+func normalizeTag(name string) (string, error) {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '_'
+	})
+	tag := strings.Join(words, "-")
+
+	if tag == "" {
+		return "", apperr.Validation("invalid_tag", "tag name cannot be empty")
+	}
+	if len([]rune(tag)) > maxTagLength {
+		return "", apperr.Validation("invalid_tag", "tag name is too long")
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.-", r) {
+			return "", apperr.Validation("invalid_tag", "tag names may only contain letters, digits and + # . -")
+		}
+	}
+	return tag, nil
+}
+
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func normalizeQuestionTags(names []string) ([]string, error) {
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > MaxTagsPerQuestion {
+		return nil, errTooManyTags
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+var errTooManyTags = apperr.Validation("too_many_tags", fmt.Sprintf("a question can have at most %d tags", MaxTagsPerQuestion))
+
+// tagNames returns the sorted names of tags, comparable with the output of
+// normalizeQuestionTags.
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	sort.Strings(names)
+	return names
+}
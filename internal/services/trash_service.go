@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+	"time"
+)
+
+type TrashService struct {
+	trashRepo repository.TrashRepository
+}
+
+func NewTrashService(trashRepo repository.TrashRepository) *TrashService {
+	return &TrashService{
+		trashRepo: trashRepo,
+	}
+}
+
+func (s *TrashService) ListTrash(ctx context.Context, opts models.TrashOptions) ([]models.TrashItem, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.ListTrash")
+	defer span.End()
+
+	opts.Limit = clampPageSize(opts.Limit)
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	return s.trashRepo.List(ctx, opts)
+}
+
+func (s *TrashService) RestoreQuestion(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreQuestion")
+	defer span.End()
+
+	err := s.trashRepo.RestoreQuestion(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return apperr.NotFound("deleted_question_not_found", "deleted question not found")
+	}
+	return err
+}
+
+func (s *TrashService) RestoreAnswer(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreAnswer")
+	defer span.End()
+
+	err := s.trashRepo.RestoreAnswer(ctx, id)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return apperr.NotFound("deleted_answer_not_found", "deleted answer not found")
+	}
+	if errors.Is(err, repository.ErrParentDeleted) {
+		return apperr.Conflict("question_deleted", "question of this answer is deleted, restore it first")
+	}
+	return err
+}
+
+// Purge permanently removes everything deleted more than retention ago.
+func (s *TrashService) Purge(ctx context.Context, retention time.Duration) (*models.PurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.Purge")
+	defer span.End()
+
+	return s.trashRepo.Purge(ctx, time.Now().Add(-retention))
+}
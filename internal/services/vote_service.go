@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/models"
+	"qa-service/internal/repository"
+	"qa-service/internal/tracing"
+)
+
+type VoteService struct {
+	voteRepo repository.VoteRepository
+}
+
+func NewVoteService(voteRepo repository.VoteRepository) *VoteService {
+	return &VoteService{
+		voteRepo: voteRepo,
+	}
+}
+
+func (s *VoteService) Vote(ctx context.Context, principal *auth.Principal, targetType string, targetID uint, req *models.VoteRequest) (*models.VoteResult, error) {
+	ctx, span := tracing.Start(ctx, "VoteService.Vote")
+	defer span.End()
+
+	userID, err := authorID(principal)
+	if err != nil {
+		return nil, err
+	}
+	if req.Value < -1 || req.Value > 1 {
+		return nil, apperr.Validation("invalid_vote_value", "vote value must be -1, 0 or 1")
+	}
+
+	vote := &models.Vote{
+		UserID:     userID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Value:      req.Value,
+	}
+
+	score, err := s.voteRepo.Cast(ctx, vote)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil, targetNotFound(targetType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VoteResult{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Score:      score,
+		UserVote:   req.Value,
+	}, nil
+}
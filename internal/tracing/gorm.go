@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// InstrumentDB adds a client span for every query run through db. Spans
+// carry the statement with its placeholders; bound values are never
+// recorded.
+func InstrumentDB(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startQuery("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endQuery),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startQuery("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endQuery),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startQuery("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endQuery),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuery("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endQuery),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startQuery("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endQuery),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startQuery("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endQuery),
+	)
+}
+
+func startQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endQuery(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if table := db.Statement.Table; table != "" {
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,168 @@
+// Package validation decodes JSON request bodies and checks them against the
+// validate tags on the request models.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"qa-service/internal/apperr"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxBodyBytes bounds a request body; every text field is far smaller.
+const MaxBodyBytes = 1 << 20
+
+var (
+	errInvalidBody  = apperr.Validation("invalid_body", "request body must be a JSON object")
+	errBodyTooLarge = apperr.Validation("body_too_large", fmt.Sprintf("request body cannot be larger than %d bytes", MaxBodyBytes))
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonName)
+	return v
+}
+
+// Decode reads the JSON body of r into dst, trims surrounding whitespace from
+// every string in it and validates the result. Unknown fields are rejected.
+// Validation failures are returned as apperr.InvalidFields.
+func Decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errInvalidBody
+	}
+
+	trimStrings(reflect.ValueOf(dst))
+	return Struct(dst)
+}
+
+// Struct validates an already populated request.
+func Struct(v interface{}) error {
+	err := validate.Struct(v)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fields := make([]apperr.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, apperr.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return apperr.InvalidFields(fields)
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return errBodyTooLarge
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return apperr.InvalidFields([]apperr.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + article(typeErr.Type.Kind()),
+		}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperr.InvalidFields([]apperr.FieldError{{Field: field, Rule: "unknown", Message: "unknown field"}})
+	}
+	return errInvalidBody
+}
+
+func message(fe validator.FieldError) string {
+	kind := fe.Kind()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if kind == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters long", fe.Param())
+	case "max":
+		if kind == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters long", fe.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	}
+	return "must satisfy " + fe.Tag()
+}
+
+func article(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "an object"
+}
+
+// fieldPath drops the struct name validator puts in front of the JSON path.
+func fieldPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func trimStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			trimStrings(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				trimStrings(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			trimStrings(v.Index(i))
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(strings.TrimSpace(v.String()))
+		}
+	}
+}
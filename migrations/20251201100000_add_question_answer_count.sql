@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN answer_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE questions q
+SET answer_count = (SELECT COUNT(*) FROM answers a WHERE a.question_id = q.id);
+
+CREATE INDEX idx_questions_created_at_id ON questions (created_at DESC, id DESC);
+CREATE INDEX idx_questions_answer_count_id ON questions (answer_count DESC, id DESC);
+
+-- +goose Down
+DROP INDEX idx_questions_answer_count_id;
+DROP INDEX idx_questions_created_at_id;
+ALTER TABLE questions DROP COLUMN answer_count;
@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE INDEX idx_answers_question_created_at_id ON answers (question_id, created_at, id);
+CREATE INDEX idx_answers_question_user_created_at_id ON answers (question_id, user_id, created_at, id);
+
+-- +goose Down
+DROP INDEX idx_answers_question_user_created_at_id;
+DROP INDEX idx_answers_question_created_at_id;
@@ -0,0 +1,39 @@
+-- +goose Up
+ALTER TABLE questions
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+UPDATE questions SET updated_at = created_at;
+
+ALTER TABLE answers
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+UPDATE answers SET updated_at = created_at;
+
+CREATE TABLE revisions (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL CHECK (entity_type IN ('question', 'answer')),
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    editor_id VARCHAR(255) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (entity_type, entity_id, version)
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION forbid_revision_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER revisions_immutable
+    BEFORE UPDATE ON revisions
+    FOR EACH ROW EXECUTE FUNCTION forbid_revision_update();
+
+-- +goose Down
+DROP TABLE revisions;
+DROP FUNCTION forbid_revision_update();
+ALTER TABLE answers DROP COLUMN updated_at, DROP COLUMN version;
+ALTER TABLE questions DROP COLUMN updated_at, DROP COLUMN version;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE answers ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE votes (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('question', 'answer')),
+    target_id INTEGER NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, target_type, target_id)
+);
+
+CREATE INDEX idx_votes_target ON votes (target_type, target_id);
+CREATE INDEX idx_answers_question_score_id ON answers (question_id, score DESC, id);
+
+-- +goose Down
+DROP INDEX idx_answers_question_score_id;
+DROP TABLE votes;
+ALTER TABLE answers DROP COLUMN score;
+ALTER TABLE questions DROP COLUMN score;
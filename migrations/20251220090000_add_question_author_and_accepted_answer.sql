@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE questions
+    ADD COLUMN user_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN accepted_answer_id INTEGER REFERENCES answers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_questions_user_id ON questions (user_id);
+
+-- +goose Down
+DROP INDEX idx_questions_user_id;
+ALTER TABLE questions DROP COLUMN accepted_answer_id, DROP COLUMN user_id;
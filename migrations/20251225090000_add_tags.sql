@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(35) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE question_tags (
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (question_id, tag_id)
+);
+
+CREATE INDEX idx_question_tags_tag_id ON question_tags (tag_id);
+
+-- +goose Down
+DROP TABLE question_tags;
+DROP TABLE tags;
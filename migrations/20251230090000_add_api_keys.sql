@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    roles TEXT NOT NULL DEFAULT 'user',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
+
+-- +goose Down
+DROP TABLE api_keys;
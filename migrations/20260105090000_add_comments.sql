@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('question', 'answer')),
+    target_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    text VARCHAR(600) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_target ON comments (target_type, target_id, created_at, id);
+
+-- +goose Down
+DROP TABLE comments;
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE answers ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_questions_deleted_at ON questions (deleted_at);
+CREATE INDEX idx_answers_deleted_at ON answers (deleted_at);
+
+-- +goose Down
+DROP INDEX idx_answers_deleted_at;
+DROP INDEX idx_questions_deleted_at;
+ALTER TABLE answers DROP COLUMN deleted_at;
+ALTER TABLE questions DROP COLUMN deleted_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Buckets are cheap to lose, so the table skips the write-ahead log.
+CREATE UNLOGGED TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    full_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
+
+-- +goose Down
+DROP TABLE rate_limit_buckets;
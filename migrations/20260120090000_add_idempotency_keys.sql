@@ -0,0 +1,15 @@
+-- +goose Up
+-- A row without a status is a request still in flight.
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    status INTEGER,
+    headers JSONB,
+    body BYTEA,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Each claim gets its own token, so a request whose claim lapsed and was
+-- taken over by a retry cannot record its outcome over the retry's.
+ALTER TABLE idempotency_keys ADD COLUMN claim CHAR(32);
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN claim;
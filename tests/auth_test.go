@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"qa-service/internal/auth"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = []byte("test-secret-do-not-use-in-production")
+
+func newTestJWTVerifier() *auth.JWTVerifier {
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", testJWTSecret)
+	return auth.NewJWTVerifier(keys, "", "")
+}
+
+// testToken returns an HS256 token for subject that the test servers accept.
+func testToken(t *testing.T, subject string, roles ...string) string {
+	claims := jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix()}
+	if len(roles) > 0 {
+		claims["roles"] = roles
+	}
+	return signTestToken(t, claims)
+}
+
+func signTestToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testJWTSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func authenticateBearer(authenticator *auth.Authenticator, token string) (*auth.Principal, error) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return authenticator.Authenticate(req)
+}
+
+func TestJWTKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{"kty": "EC", "kid": "ignored"},
+		},
+	})
+	jwksPath := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksPath, jwks, 0o600))
+	secretPath := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretPath, append(testJWTSecret, '\n'), 0o600))
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.LoadJWKSFile(jwksPath))
+	require.NoError(t, keys.LoadHMACFile(secretPath))
+	authenticator := auth.NewAuthenticator(auth.NewJWTVerifier(keys, "https://issuer.test", "qa-service"), nil)
+
+	claims := jwt.MapClaims{
+		"sub":   "carol",
+		"roles": []string{auth.RoleModerator},
+		"iss":   "https://issuer.test",
+		"aud":   "qa-service",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "rsa-1"
+	signed, err := token.SignedString(rsaKey)
+	require.NoError(t, err)
+
+	principal, err := authenticateBearer(authenticator, signed)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", principal.Subject)
+	assert.True(t, principal.HasRole(auth.RoleModerator))
+	assert.Equal(t, auth.MethodJWT, principal.Method)
+
+	principal, err = authenticateBearer(authenticator, signTestToken(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "carol", principal.Subject)
+
+	token.Header["kid"] = "rsa-2"
+	signed, err = token.SignedString(rsaKey)
+	require.NoError(t, err)
+	_, err = authenticateBearer(authenticator, signed)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	claims["aud"] = "someone-else"
+	_, err = authenticateBearer(authenticator, signTestToken(t, claims))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authenticateBearer(authenticator, signTestToken(t, jwt.MapClaims{"sub": "dave"}))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
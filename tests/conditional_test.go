@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"qa-service/internal/auth"
+	"qa-service/internal/problem"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *MemoryTestSuite) TestConditionalGet() {
+	question := suite.createQuestion("Cached?")
+	questionURL := fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID)
+
+	resp := suite.send("GET", questionURL, "", nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Regexp(suite.T(), `^"1-[0-9]+"$`, etag)
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	require.NoError(suite.T(), err)
+
+	for name, headers := range map[string]map[string]string{
+		"matching tag":         {"If-None-Match": etag},
+		"tag in a list":        {"If-None-Match": `"other", ` + etag},
+		"weak comparison":      {"If-None-Match": "W/" + etag},
+		"any tag":              {"If-None-Match": "*"},
+		"same date":            {"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+		"later date":           {"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+		"tag wins over a date": {"If-None-Match": etag, "If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+	} {
+		resp = suite.sendWithHeaders("GET", questionURL, "", headers, nil)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusNotModified, resp.StatusCode, name)
+		assert.Empty(suite.T(), body, name)
+		assert.Equal(suite.T(), etag, resp.Header.Get("ETag"), name)
+	}
+
+	resp = suite.sendWithHeaders("GET", questionURL, "", map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "changed since an earlier date")
+
+	// Last-Modified has whole seconds, so the vote has to land in a later
+	// one for If-Modified-Since to see it.
+	time.Sleep(time.Until(lastModified.Add(time.Second)))
+	resp = suite.send("PUT", questionURL+"/vote", testToken(suite.T(), "voter"), map[string]int{"value": 1})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	for name, headers := range map[string]map[string]string{
+		"If-None-Match":     {"If-None-Match": etag},
+		"If-Modified-Since": {"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+	} {
+		resp = suite.sendWithHeaders("GET", questionURL, "", headers, nil)
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "a vote changes the representation: %s", name)
+		assert.NotEqual(suite.T(), etag, resp.Header.Get("ETag"))
+		assert.Regexp(suite.T(), `^"1-[0-9]+"$`, resp.Header.Get("ETag"), "a vote leaves the version alone")
+	}
+	etag = resp.Header.Get("ETag")
+
+	first := suite.createAnswer(question.ID, "helper", "An answer")
+	resp = suite.sendWithHeaders("GET", questionURL, "", map[string]string{"If-None-Match": etag}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "a new answer changes the representation")
+	etag = resp.Header.Get("ETag")
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, first.ID), testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	resp = suite.sendWithHeaders("GET", questionURL, "", map[string]string{"If-None-Match": etag}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "deleting an answer changes the representation")
+
+	answer := suite.createAnswer(question.ID, "helper", "Another answer")
+	answerURL := fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID)
+	resp = suite.send("GET", answerURL, "", nil)
+	resp.Body.Close()
+	etag = resp.Header.Get("ETag")
+	assert.Regexp(suite.T(), `^"1-[0-9]+"$`, etag)
+	assert.NotEmpty(suite.T(), resp.Header.Get("Last-Modified"))
+	resp = suite.sendWithHeaders("GET", answerURL, "", map[string]string{"If-None-Match": etag}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotModified, resp.StatusCode)
+
+	resp = suite.send("PUT", answerURL+"/vote", testToken(suite.T(), "voter"), map[string]int{"value": 1})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp = suite.sendWithHeaders("GET", answerURL, "", map[string]string{"If-None-Match": etag}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "a vote changes the answer's representation")
+}
+
+func (suite *MemoryTestSuite) TestIfMatch() {
+	question := suite.createQuestionAs("asker", "Guarded?")
+	questionURL := fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID)
+	token := testToken(suite.T(), "asker")
+	moderator := testToken(suite.T(), "moderator", auth.RoleModerator)
+
+	resp := suite.send("GET", questionURL+"?answers_limit=1", "", nil)
+	resp.Body.Close()
+	stale := resp.Header.Get("ETag")
+
+	resp = suite.send("PUT", questionURL+"/vote", testToken(suite.T(), "voter"), map[string]int{"value": 1})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp = suite.sendWithHeaders("PATCH", questionURL, token, map[string]string{"If-Match": stale}, map[string]string{"text": "Guarded, edited?"})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode, "votes by others do not fail an edit")
+	current := resp.Header.Get("ETag")
+	assert.Regexp(suite.T(), `^"2-[0-9]+"$`, current)
+	assert.NotEmpty(suite.T(), resp.Header.Get("Last-Modified"))
+
+	resp = suite.sendWithHeaders("PATCH", questionURL, token, map[string]string{"If-Match": stale}, map[string]string{"text": "Clobbered?"})
+	var details problem.Details
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&details))
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode)
+	assert.Equal(suite.T(), "precondition_failed", details.Code)
+
+	answer := suite.createAnswer(question.ID, "helper", "Accept me")
+	for name, send := range map[string]func(headers map[string]string) *http.Response{
+		"delete": func(headers map[string]string) *http.Response {
+			return suite.sendWithHeaders("DELETE", questionURL, moderator, headers, nil)
+		},
+		"rollback": func(headers map[string]string) *http.Response {
+			return suite.sendWithHeaders("POST", questionURL+"/revisions/1/rollback", token, headers, nil)
+		},
+		"accept": func(headers map[string]string) *http.Response {
+			return suite.sendWithHeaders("PUT", questionURL+"/accepted-answer", token, headers, map[string]uint{"answer_id": answer.ID})
+		},
+		"unaccept": func(headers map[string]string) *http.Response {
+			return suite.sendWithHeaders("DELETE", questionURL+"/accepted-answer", token, headers, nil)
+		},
+	} {
+		resp = send(map[string]string{"If-Match": stale})
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode, name)
+		resp = send(map[string]string{"If-Match": "W/" + current})
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode, "%s: weak tags never match If-Match", name)
+	}
+
+	resp = suite.send("GET", questionURL, "", nil)
+	var body map[string]interface{}
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&body))
+	resp.Body.Close()
+	assert.Equal(suite.T(), "Guarded, edited?", body["text"])
+	assert.Nil(suite.T(), body["accepted_answer_id"])
+
+	resp = suite.sendWithHeaders("PUT", questionURL+"/accepted-answer", token, map[string]string{"If-Match": current}, map[string]uint{"answer_id": answer.ID})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Regexp(suite.T(), `^"2-[0-9]+"$`, resp.Header.Get("ETag"))
+	resp = suite.sendWithHeaders("POST", questionURL+"/revisions/1/rollback", token, map[string]string{"If-Match": resp.Header.Get("ETag")}, nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode, "the tag of a write response guards the next one")
+	current = resp.Header.Get("ETag")
+	assert.Regexp(suite.T(), `^"3-[0-9]+"$`, current)
+
+	resp = suite.sendWithHeaders("DELETE", questionURL, moderator, map[string]string{"If-Match": current}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	resp = suite.sendWithHeaders("PATCH", questionURL, token, map[string]string{"If-Match": "*"}, map[string]string{"text": "Still there?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode, "a missing question fails any If-Match")
+	resp = suite.send("PATCH", questionURL, token, map[string]string{"text": "Still there?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	other := suite.createQuestionAs("asker", "Anything goes?")
+	answer = suite.createAnswer(other.ID, "helper", "Guarded answer")
+	answerURL := fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID)
+	helper := testToken(suite.T(), "helper")
+	resp = suite.send("GET", answerURL, "", nil)
+	resp.Body.Close()
+	stale = resp.Header.Get("ETag")
+
+	resp = suite.sendWithHeaders("PATCH", answerURL, helper, map[string]string{"If-Match": stale}, map[string]string{"text": "Guarded answer, edited"})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	current = resp.Header.Get("ETag")
+	assert.Regexp(suite.T(), `^"2-[0-9]+"$`, current)
+
+	for name, req := range map[string][2]string{
+		"edit":     {"PATCH", answerURL},
+		"rollback": {"POST", answerURL + "/revisions/1/rollback"},
+		"delete":   {"DELETE", answerURL},
+	} {
+		resp = suite.sendWithHeaders(req[0], req[1], helper, map[string]string{"If-Match": stale}, map[string]string{"text": "Clobbered"})
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode, name)
+	}
+
+	resp = suite.sendWithHeaders("POST", answerURL+"/revisions/1/rollback", helper, map[string]string{"If-Match": current}, nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Regexp(suite.T(), `^"3-[0-9]+"$`, resp.Header.Get("ETag"))
+	resp = suite.sendWithHeaders("DELETE", answerURL, helper, map[string]string{"If-Match": "*"}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	resp = suite.sendWithHeaders("DELETE", answerURL, helper, map[string]string{"If-Match": "*"}, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, resp.StatusCode, "a missing answer fails any If-Match")
+}
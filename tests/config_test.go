@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"qa-service/internal/config"
+	"qa-service/internal/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadConfig(t *testing.T, args []string, env map[string]string) (*config.Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return config.Load(fs, args, func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(t, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  port: 9000
+  read_timeout: 5s
+  route_timeouts:
+    get /api/v1/search: 2s
+database:
+  host: db.internal
+  name: from_file
+log:
+  level: debug
+`)
+
+	cfg, err := loadConfig(t, []string{"--server.port=9100", "--features.metrics=false"}, map[string]string{
+		"CONFIG_FILE": path,
+		"PORT":        "9050",
+		"DB_NAME":     "from_env",
+		"LOG_LEVEL":   "",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 9100, cfg.Server.Port, "flags override the environment")
+	assert.Equal(t, "from_env", cfg.Database.Name, "the environment overrides the file")
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "debug", cfg.Log.Level, "empty variables are ignored")
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 15*time.Second, cfg.Server.WriteTimeout, "unset keys keep their default")
+	assert.Equal(t, config.RouteTimeouts{"GET /api/v1/search": 2 * time.Second}, cfg.Server.RouteTimeouts)
+	assert.False(t, cfg.Features.Metrics)
+}
+
+func TestConfigTOMLFile(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+storage = "memory"
+
+[server]
+port = 9000
+request_timeout = "3s"
+
+[server.route_timeouts]
+"POST /api/v1/questions" = "1m"
+
+[features]
+trash_purge = false
+`)
+
+	cfg, err := loadConfig(t, []string{"--config", path}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.StorageMemory, cfg.Storage)
+	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, 3*time.Second, cfg.Server.RequestTimeout)
+	assert.Equal(t, config.RouteTimeouts{"POST /api/v1/questions": time.Minute}, cfg.Server.RouteTimeouts)
+	assert.False(t, cfg.Features.TrashPurge)
+}
+
+func TestConfigRejectsUnknownKeys(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.yaml": "server:\n  prot: 9000\n",
+		"config.toml": "[server]\nprot = 9000\n",
+		"config.json": "{}",
+	} {
+		_, err := loadConfig(t, []string{"--config", writeConfigFile(t, name, content)}, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := loadConfig(t, nil, map[string]string{
+		"PORT":                 "70000",
+		"LOG_FORMAT":           "xml",
+		"TRACING_SAMPLE_RATIO": "2",
+		"DB_MAX_OPEN_CONNS":    "2",
+		"DB_MAX_IDLE_CONNS":    "5",
+	})
+	require.Error(t, err)
+
+	var validationErr *config.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Errors, 4)
+	assert.Contains(t, err.Error(), "server.port: must be between 1 and 65535, got 70000")
+	assert.Contains(t, err.Error(), "log.format:")
+	assert.Contains(t, err.Error(), "tracing.sample_ratio:")
+	assert.Contains(t, err.Error(), "database.max_idle_conns:")
+
+	_, err = loadConfig(t, nil, map[string]string{"SERVER_READ_TIMEOUT": "soon"})
+	assert.ErrorContains(t, err, "SERVER_READ_TIMEOUT")
+
+	_, err = loadConfig(t, []string{"--server.port=abc"}, nil)
+	assert.Error(t, err)
+}
+
+func TestPrintConfigRedactsSecrets(t *testing.T) {
+	cfg, err := loadConfig(t, nil, map[string]string{
+		"DATABASE_URL": "postgres://qa:hunter2@db/qa",
+		"DB_PASSWORD":  "hunter2",
+	})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, config.Print(&out, cfg))
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Contains(t, out.String(), "REDACTED")
+	assert.Contains(t, out.String(), "port: 8080")
+	assert.Equal(t, "hunter2", cfg.Database.Password, "printing must not change the configuration")
+}
+
+func TestParseRouteTimeouts(t *testing.T) {
+	timeouts, err := config.ParseRouteTimeouts("GET /api/v1/search=2s, post /api/v1/admin/tags/{name}/merge=1m,")
+	require.NoError(t, err)
+	assert.Equal(t, config.RouteTimeouts{
+		"GET /api/v1/search":                   2 * time.Second,
+		"POST /api/v1/admin/tags/{name}/merge": time.Minute,
+	}, timeouts)
+
+	for _, spec := range []string{"/api/v1/search=2s", "GET /api/v1/search", "GET /api/v1/search=soon", "GET /api/v1/search=-1s"} {
+		_, err := config.ParseRouteTimeouts(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestConfigRateLimit(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+rate_limit:
+  policies:
+    read: 100/m
+  trusted_proxies: [10.0.0.0/8, "::1"]
+`)
+
+	cfg, err := loadConfig(t, []string{"--config", path}, map[string]string{"RATE_LIMITS": "create=5/10s:2"})
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimitPolicies{
+		ratelimit.GroupRead:   {Limit: 100, Period: time.Minute, Burst: 100},
+		ratelimit.GroupWrite:  config.Default().RateLimit.Policies[ratelimit.GroupWrite],
+		ratelimit.GroupCreate: {Limit: 5, Period: 10 * time.Second, Burst: 2},
+	}, cfg.RateLimit.Policies, "groups left out keep their policy")
+	assert.Equal(t, config.Prefixes{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}, cfg.RateLimit.TrustedProxies)
+
+	cfg, err = loadConfig(t, nil, map[string]string{"TRUSTED_PROXIES": "192.168.1.7, 172.16.0.0/12"})
+	require.NoError(t, err)
+	assert.Equal(t, config.Prefixes{netip.MustParsePrefix("192.168.1.7/32"), netip.MustParsePrefix("172.16.0.0/12")}, cfg.RateLimit.TrustedProxies)
+
+	_, err = loadConfig(t, nil, map[string]string{"RATE_LIMITS": "search=1/s"})
+	assert.ErrorContains(t, err, `rate_limit.policies: unknown group "search"`)
+	_, err = loadConfig(t, nil, map[string]string{"RATE_LIMIT_STORE": "postgres", "STORAGE_BACKEND": "memory"})
+	assert.ErrorContains(t, err, "rate_limit.store:")
+	_, err = loadConfig(t, nil, map[string]string{"TRUSTED_PROXIES": "proxy.internal"})
+	assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"qa-service/internal/health"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeReport(t *testing.T) {
+	probe := health.NewProbe(20*time.Millisecond,
+		health.Check{Name: "database", Run: func(ctx context.Context) error { return nil }},
+		health.Check{Name: "migrations", Run: func(ctx context.Context) error { return errors.New("schema version 1 is behind 2") }},
+		health.Check{Name: "slow", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	)
+
+	report := probe.Ready(context.Background())
+	assert.Equal(t, health.StatusFailed, report.Status)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusFailed, LatencyMS: report.Checks["migrations"].LatencyMS, Error: "schema version 1 is behind 2"}, report.Checks["migrations"])
+	assert.Equal(t, health.StatusFailed, report.Checks["slow"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+	assert.GreaterOrEqual(t, report.Checks["slow"].LatencyMS, float64(20))
+
+	probe.Drain()
+	assert.Equal(t, health.Report{Status: health.StatusDraining}, probe.Ready(context.Background()))
+}
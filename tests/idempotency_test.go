@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"qa-service/internal/idempotency"
+	"qa-service/internal/models"
+	"qa-service/internal/problem"
+	"qa-service/internal/routes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := idempotency.NewMemoryStore(func() time.Time { return now })
+	ctx := context.Background()
+
+	claim, stored, err := store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, stored, "the first request claims the key")
+	assert.NotEmpty(t, claim)
+
+	_, _, err = store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	assert.ErrorIs(t, err, idempotency.ErrInFlight)
+	_, _, err = store.Begin(ctx, "alice k1", "body-b", time.Minute)
+	assert.ErrorIs(t, err, idempotency.ErrMismatch)
+
+	resp := idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`)}
+	require.NoError(t, store.Complete(ctx, "alice k1", claim, resp, time.Hour))
+	_, stored, err = store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &resp, stored)
+	require.NoError(t, store.Release(ctx, "alice k1", claim))
+	_, stored, err = store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &resp, stored, "a completed key cannot be released")
+
+	now = now.Add(time.Hour)
+	_, stored, err = store.Begin(ctx, "alice k1", "body-b", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, stored, "expired keys can be used again")
+
+	now = now.Add(time.Minute)
+	claim, stored, err = store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, stored, "a claim that was never completed lapses")
+
+	require.NoError(t, store.Release(ctx, "alice k1", claim))
+	_, stored, err = store.Begin(ctx, "alice k1", "body-b", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, stored, "released keys can be retried")
+}
+
+func TestMemoryIdempotencyStoreLapsedClaim(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := idempotency.NewMemoryStore(func() time.Time { return now })
+	ctx := context.Background()
+
+	slow, _, err := store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	retry, _, err := store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+
+	late := idempotency.Response{Status: http.StatusCreated, Body: []byte(`{"id":1}`)}
+	require.NoError(t, store.Complete(ctx, "alice k1", slow, late, time.Hour))
+	require.NoError(t, store.Release(ctx, "alice k1", slow))
+	_, _, err = store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	assert.ErrorIs(t, err, idempotency.ErrInFlight, "the lapsed claim leaves the retry's alone")
+
+	resp := idempotency.Response{Status: http.StatusCreated, Body: []byte(`{"id":2}`)}
+	require.NoError(t, store.Complete(ctx, "alice k1", retry, resp, time.Hour))
+	require.NoError(t, store.Complete(ctx, "alice k1", slow, late, time.Hour))
+	_, stored, err := store.Begin(ctx, "alice k1", "body-a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &resp, stored)
+}
+
+func (suite *MemoryTestSuite) idempotentServer() *httptest.Server {
+	server := httptest.NewServer(suite.router(routes.RateLimit{}, routes.Idempotency{
+		Store:       idempotency.NewMemoryStore(nil),
+		TTL:         time.Hour,
+		LockTimeout: time.Minute,
+	}))
+	suite.T().Cleanup(server.Close)
+	return server
+}
+
+func (suite *MemoryTestSuite) sendIdempotent(url, userID, key string, body interface{}) (*http.Response, []byte) {
+	reqBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testToken(suite.T(), userID))
+	req.Header.Set("Idempotency-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(suite.T(), err)
+	return resp, respBody
+}
+
+func (suite *MemoryTestSuite) TestIdempotencyKey() {
+	server := suite.idempotentServer()
+	questionsURL := server.URL + "/api/v1/questions/"
+
+	first, firstBody := suite.sendIdempotent(questionsURL, "alice", "retry-1", map[string]string{"text": "Asked once?"})
+	require.Equal(suite.T(), http.StatusCreated, first.StatusCode)
+	assert.Empty(suite.T(), first.Header.Get("Idempotent-Replayed"))
+
+	retry, retryBody := suite.sendIdempotent(questionsURL, "alice", "retry-1", map[string]string{"text": "Asked once?"})
+	assert.Equal(suite.T(), http.StatusCreated, retry.StatusCode)
+	assert.Equal(suite.T(), "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.Equal(suite.T(), first.Header.Get("Content-Type"), retry.Header.Get("Content-Type"))
+	assert.Equal(suite.T(), firstBody, retryBody)
+	assert.NotEqual(suite.T(), first.Header.Get("X-Request-ID"), retry.Header.Get("X-Request-ID"))
+	assert.Equal(suite.T(), 1, len(suite.listQuestions("").Items), "the retry did not create another question")
+
+	reused, reusedBody := suite.sendIdempotent(questionsURL, "alice", "retry-1", map[string]string{"text": "Something else?"})
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, reused.StatusCode)
+	var details problem.Details
+	require.NoError(suite.T(), json.Unmarshal(reusedBody, &details))
+	assert.Equal(suite.T(), "idempotency_key_reused", details.Code)
+
+	other, _ := suite.sendIdempotent(questionsURL, "bob", "retry-1", map[string]string{"text": "Asked once?"})
+	assert.Equal(suite.T(), http.StatusCreated, other.StatusCode, "keys are scoped to the caller")
+	assert.Equal(suite.T(), 2, len(suite.listQuestions("").Items))
+
+	var question models.Question
+	require.NoError(suite.T(), json.Unmarshal(firstBody, &question))
+	answersURL := fmt.Sprintf("%s/api/v1/questions/%d/answers/", server.URL, question.ID)
+	answer, answerBody := suite.sendIdempotent(answersURL, "alice", "retry-1", map[string]string{"text": "An answer"})
+	assert.Equal(suite.T(), http.StatusCreated, answer.StatusCode, "keys are scoped to the route")
+	retry, retryBody = suite.sendIdempotent(answersURL, "alice", "retry-1", map[string]string{"text": "An answer"})
+	assert.Equal(suite.T(), http.StatusCreated, retry.StatusCode)
+	assert.Equal(suite.T(), answerBody, retryBody)
+
+	otherQuestionURL := fmt.Sprintf("%s/api/v1/questions/%d/answers/", server.URL, question.ID+1)
+	reused, _ = suite.sendIdempotent(otherQuestionURL, "alice", "retry-1", map[string]string{"text": "An answer"})
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, reused.StatusCode, "the key is bound to the question answered")
+
+	invalid, _ := suite.sendIdempotent(questionsURL, "alice", strings.Repeat("k", 256), map[string]string{"text": "Too long a key?"})
+	assert.Equal(suite.T(), http.StatusBadRequest, invalid.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestIdempotencyKeyStoresClientErrors() {
+	server := suite.idempotentServer()
+	questionsURL := server.URL + "/api/v1/questions/"
+
+	first, firstBody := suite.sendIdempotent(questionsURL, "alice", "empty", map[string]string{"text": ""})
+	require.Equal(suite.T(), http.StatusBadRequest, first.StatusCode)
+	retry, retryBody := suite.sendIdempotent(questionsURL, "alice", "empty", map[string]string{"text": ""})
+	assert.Equal(suite.T(), http.StatusBadRequest, retry.StatusCode)
+	assert.Equal(suite.T(), "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.Equal(suite.T(), firstBody, retryBody)
+}
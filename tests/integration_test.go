@@ -2,21 +2,37 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/database"
 	"qa-service/internal/handlers"
+	"qa-service/internal/health"
+	"qa-service/internal/idempotency"
+	"qa-service/internal/logging"
+	"qa-service/internal/metrics"
 	"qa-service/internal/models"
+	"qa-service/internal/policy"
+	"qa-service/internal/ratelimit"
 	"qa-service/internal/repository"
 	"qa-service/internal/routes"
 	"qa-service/internal/services"
+	"qa-service/internal/tracing"
+	"qa-service/migrations"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -26,6 +42,7 @@ type IntegrationTestSuite struct {
 	db         *gorm.DB
 	router     http.Handler
 	testServer *httptest.Server
+	metrics    *metrics.Metrics
 }
 
 func (suite *IntegrationTestSuite) SetupSuite() {
@@ -41,24 +58,61 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 		return
 	}
 
-	err = suite.db.AutoMigrate(&models.Question{}, &models.Answer{})
+	migrator, err := database.NewMigrator(suite.db)
 	if err != nil {
+		suite.T().Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
 		suite.T().Fatalf("Failed to migrate test database: %v", err)
 	}
 
+	suite.db.Exec("DELETE FROM comments")
+	suite.db.Exec("DELETE FROM api_keys")
+	suite.db.Exec("DELETE FROM question_tags")
+	suite.db.Exec("DELETE FROM tags")
+	suite.db.Exec("DELETE FROM votes")
+	suite.db.Exec("DELETE FROM revisions")
 	suite.db.Exec("DELETE FROM answers")
 	suite.db.Exec("DELETE FROM questions")
 
-	questionRepo := repository.NewQuestionRepository(suite.db)
-	answerRepo := repository.NewAnswerRepository(suite.db)
-	questionService := services.NewQuestionService(questionRepo)
-	answerService := services.NewAnswerService(answerRepo, questionRepo)
+	suite.metrics = metrics.New()
+	if err := suite.metrics.InstrumentDB(suite.db, "qa_service_test"); err != nil {
+		suite.T().Fatalf("Failed to instrument test database: %v", err)
+	}
+	if err := tracing.InstrumentDB(suite.db); err != nil {
+		suite.T().Fatalf("Failed to trace test database: %v", err)
+	}
 
-	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
-	questionHandler := handlers.NewQuestionHandler(questionService, logger)
-	answerHandler := handlers.NewAnswerHandler(answerService, logger)
+	repos := repository.NewRepositories(suite.db)
+	questionService := services.NewQuestionService(repos.Questions, repos.Answers, repos.Revisions, policy.Default(), suite.metrics)
+	answerService := services.NewAnswerService(repos.Answers, repos.Questions, repos.Revisions, policy.Default(), suite.metrics)
+	searchService := services.NewSearchService(repos.Search)
+	voteService := services.NewVoteService(repos.Votes)
+	tagService := services.NewTagService(repos.Tags)
+	apiKeyService := services.NewAPIKeyService(repos.APIKeys)
+	commentService := services.NewCommentService(repos.Comments, repos.Questions, repos.Answers, policy.Default())
+	trashService := services.NewTrashService(repos.Trash)
+
+	logger, err := logging.New(os.Stdout, logging.FormatText, "debug")
+	suite.Require().NoError(err)
+	probe := health.NewProbe(time.Second, health.Check{
+		Name: "database",
+		Run:  func(ctx context.Context) error { return database.Ping(ctx, suite.db) },
+	})
+	handlerSet := routes.Handlers{
+		Questions: handlers.NewQuestionHandler(questionService, logger),
+		Answers:   handlers.NewAnswerHandler(answerService, logger),
+		Search:    handlers.NewSearchHandler(searchService, logger),
+		Votes:     handlers.NewVoteHandler(voteService, logger),
+		Tags:      handlers.NewTagHandler(tagService, logger),
+		APIKeys:   handlers.NewAPIKeyHandler(apiKeyService, logger),
+		Comments:  handlers.NewCommentHandler(commentService, logger),
+		Trash:     handlers.NewTrashHandler(trashService, logger),
+		Health:    handlers.NewHealthHandler(probe, logger),
+	}
 
-	router := routes.SetupRoutes(questionHandler, answerHandler, logger)
+	authenticator := auth.NewAuthenticator(newTestJWTVerifier(), apiKeyService)
+	router := routes.SetupRoutes(handlerSet, authenticator, policy.Default(), routes.Timeouts{}, routes.RateLimit{}, routes.Idempotency{}, suite.metrics, logger)
 	suite.router = router
 	suite.testServer = httptest.NewServer(router)
 }
@@ -74,6 +128,12 @@ func (suite *IntegrationTestSuite) TearDownSuite() {
 }
 
 func (suite *IntegrationTestSuite) TearDownTest() {
+	suite.db.Exec("DELETE FROM comments")
+	suite.db.Exec("DELETE FROM api_keys")
+	suite.db.Exec("DELETE FROM question_tags")
+	suite.db.Exec("DELETE FROM tags")
+	suite.db.Exec("DELETE FROM votes")
+	suite.db.Exec("DELETE FROM revisions")
 	suite.db.Exec("DELETE FROM answers")
 	suite.db.Exec("DELETE FROM questions")
 }
@@ -114,11 +174,22 @@ func TestDatabaseConnection(t *testing.T) {
 	db.Delete(question)
 }
 
+func (suite *IntegrationTestSuite) post(url string, body []byte) (*http.Response, error) {
+	return suite.postAs("tester", url, body)
+}
+
+func (suite *IntegrationTestSuite) postAs(userID, url string, body []byte) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testToken(suite.T(), userID))
+	return http.DefaultClient.Do(req)
+}
+
 func (suite *IntegrationTestSuite) TestCreateAndGetQuestion() {
 	questionReq := map[string]string{"text": "Test question?"}
 	reqBody, _ := json.Marshal(questionReq)
 
-	resp, err := http.Post(suite.testServer.URL+"/api/v1/questions/", "application/json", bytes.NewBuffer(reqBody))
+	resp, err := suite.post(suite.testServer.URL+"/api/v1/questions/", reqBody)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
 
@@ -149,7 +220,7 @@ func (suite *IntegrationTestSuite) TestGetAllQuestions() {
 	for _, q := range questions {
 		req := map[string]string{"text": q}
 		reqBody, _ := json.Marshal(req)
-		resp, _ := http.Post(suite.testServer.URL+"/api/v1/questions/", "application/json", bytes.NewBuffer(reqBody))
+		resp, _ := suite.post(suite.testServer.URL+"/api/v1/questions/", reqBody)
 		resp.Body.Close()
 	}
 
@@ -157,19 +228,19 @@ func (suite *IntegrationTestSuite) TestGetAllQuestions() {
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
-	var retrievedQuestions []models.Question
-	err = json.NewDecoder(resp.Body).Decode(&retrievedQuestions)
+	var page models.QuestionPage
+	err = json.NewDecoder(resp.Body).Decode(&page)
 	assert.NoError(suite.T(), err)
 	resp.Body.Close()
 
-	assert.Len(suite.T(), retrievedQuestions, 3)
+	assert.Len(suite.T(), page.Items, 3)
 }
 
 func (suite *IntegrationTestSuite) TestCreateAnswer() {
 	questionReq := map[string]string{"text": "Test question for answer?"}
 	reqBody, _ := json.Marshal(questionReq)
 
-	resp, err := http.Post(suite.testServer.URL+"/api/v1/questions/", "application/json", bytes.NewBuffer(reqBody))
+	resp, err := suite.post(suite.testServer.URL+"/api/v1/questions/", reqBody)
 	assert.NoError(suite.T(), err)
 
 	var question models.Question
@@ -177,13 +248,10 @@ func (suite *IntegrationTestSuite) TestCreateAnswer() {
 	assert.NoError(suite.T(), err)
 	resp.Body.Close()
 
-	answerReq := map[string]string{
-		"user_id": "user123",
-		"text":    "This is an answer",
-	}
+	answerReq := map[string]string{"text": "This is an answer"}
 	reqBody, _ = json.Marshal(answerReq)
 
-	resp, err = http.Post(suite.testServer.URL+"/api/v1/questions/"+fmt.Sprintf("%d", question.ID)+"/answers/", "application/json", bytes.NewBuffer(reqBody))
+	resp, err = suite.postAs("user123", suite.testServer.URL+"/api/v1/questions/"+fmt.Sprintf("%d", question.ID)+"/answers/", reqBody)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
 
@@ -201,7 +269,7 @@ func (suite *IntegrationTestSuite) TestDeleteQuestion() {
 	questionReq := map[string]string{"text": "Question to delete"}
 	reqBody, _ := json.Marshal(questionReq)
 
-	resp, err := http.Post(suite.testServer.URL+"/api/v1/questions/", "application/json", bytes.NewBuffer(reqBody))
+	resp, err := suite.post(suite.testServer.URL+"/api/v1/questions/", reqBody)
 	assert.NoError(suite.T(), err)
 
 	var question models.Question
@@ -210,6 +278,7 @@ func (suite *IntegrationTestSuite) TestDeleteQuestion() {
 	resp.Body.Close()
 
 	req, _ := http.NewRequest("DELETE", suite.testServer.URL+"/api/v1/questions/"+fmt.Sprintf("%d", question.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(suite.T(), "tester"))
 	resp, err = http.DefaultClient.Do(req)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
@@ -219,6 +288,165 @@ func (suite *IntegrationTestSuite) TestDeleteQuestion() {
 	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
 }
 
+func (suite *IntegrationTestSuite) TestQueryDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := repository.NewQuestionRepository(suite.db).GetByID(ctx, 1)
+	assert.ErrorIs(suite.T(), err, apperr.ErrTimeout)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = repository.NewQuestionRepository(suite.db).GetByID(ctx, 1)
+	assert.ErrorIs(suite.T(), err, apperr.ErrUnavailable)
+}
+
+func (suite *IntegrationTestSuite) TestDatabaseMetrics() {
+	reqBody, _ := json.Marshal(map[string]string{"text": "Measured question"})
+	resp, err := suite.post(suite.testServer.URL+"/api/v1/questions/", reqBody)
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+
+	body := scrapeMetrics(suite.T(), suite.testServer.URL)
+	assert.Contains(suite.T(), body, `db_query_duration_seconds_count{operation="create",table="questions"}`)
+	assert.Contains(suite.T(), body, `go_sql_open_connections{db_name="qa_service_test"}`)
+}
+
+func (suite *IntegrationTestSuite) TestDatabaseSpans() {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	_, err := repository.NewQuestionRepository(suite.db).Exists(context.Background(), 424242)
+	assert.NoError(suite.T(), err)
+
+	var query sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "gorm.query" || span.Name() == "gorm.row" {
+			query = span
+		}
+	}
+	if assert.NotNil(suite.T(), query) {
+		for _, attr := range query.Attributes() {
+			if attr.Key == "db.query.text" {
+				assert.Contains(suite.T(), attr.Value.AsString(), "$1")
+				assert.NotContains(suite.T(), attr.Value.AsString(), "424242")
+			}
+		}
+	}
+}
+
+func (suite *IntegrationTestSuite) TestReadiness() {
+	resp, err := http.Get(suite.testServer.URL + "/readyz")
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var report health.Report
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(suite.T(), health.StatusOK, report.Checks["database"].Status)
+}
+
+func (suite *IntegrationTestSuite) TestMigrations() {
+	ctx := context.Background()
+	migrator, err := database.NewMigrator(suite.db)
+	suite.Require().NoError(err)
+
+	latest, err := migrations.Latest()
+	suite.Require().NoError(err)
+	version, err := migrator.GetDBVersion(ctx)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), latest, version)
+
+	// Redo the latest migration to check that it rolls back cleanly.
+	down, err := migrator.Down(ctx)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), latest, down.Source.Version)
+	pending, err := migrator.HasPending(ctx)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), pending)
+
+	// Concurrent runners wait for the advisory lock instead of applying
+	// the same migration twice.
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			m, err := database.NewMigrator(suite.db)
+			if err == nil {
+				_, err = m.Up(ctx)
+			}
+			errs <- err
+		}()
+	}
+	assert.NoError(suite.T(), <-errs)
+	assert.NoError(suite.T(), <-errs)
+
+	pending, err = migrator.HasPending(ctx)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), pending)
+}
+
+func (suite *IntegrationTestSuite) TestSchemaMatchesModels() {
+	assertNoSchemaDrift(suite.T(), suite.db)
+}
+
+func (suite *IntegrationTestSuite) TestPostgresRateLimitStore() {
+	ctx := context.Background()
+	suite.db.Exec("DELETE FROM rate_limit_buckets")
+	policy := ratelimit.Policy{Limit: 2, Period: time.Hour, Burst: 2}
+	// Two stores stand in for two replicas sharing the database.
+	replicas := []*ratelimit.PostgresStore{ratelimit.NewPostgresStore(suite.db), ratelimit.NewPostgresStore(suite.db)}
+
+	for i, store := range replicas {
+		result, err := store.Take(ctx, "write:user:alice", policy)
+		suite.Require().NoError(err)
+		assert.True(suite.T(), result.Allowed)
+		assert.Equal(suite.T(), 1-i, result.Remaining)
+	}
+	result, err := replicas[0].Take(ctx, "write:user:alice", policy)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), result.Allowed, "the replicas share one bucket")
+	assert.InDelta(suite.T(), 30*time.Minute, result.RetryAfter, float64(time.Minute))
+
+	result, err = replicas[1].Take(ctx, "write:user:bob", policy)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), result.Allowed)
+}
+
+func (suite *IntegrationTestSuite) TestPostgresIdempotencyStore() {
+	ctx := context.Background()
+	suite.db.Exec("DELETE FROM idempotency_keys")
+	// Two stores stand in for two replicas sharing the database.
+	first, second := idempotency.NewPostgresStore(suite.db), idempotency.NewPostgresStore(suite.db)
+
+	claim, stored, err := first.Begin(ctx, "alice k1", strings.Repeat("a", 64), time.Minute)
+	suite.Require().NoError(err)
+	assert.Nil(suite.T(), stored)
+	_, _, err = second.Begin(ctx, "alice k1", strings.Repeat("a", 64), time.Minute)
+	assert.ErrorIs(suite.T(), err, idempotency.ErrInFlight)
+	_, _, err = second.Begin(ctx, "alice k1", strings.Repeat("b", 64), time.Minute)
+	assert.ErrorIs(suite.T(), err, idempotency.ErrMismatch)
+
+	resp := idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`)}
+	suite.Require().NoError(second.Complete(ctx, "alice k1", "other claim", resp, time.Hour))
+	_, _, err = second.Begin(ctx, "alice k1", strings.Repeat("a", 64), time.Minute)
+	assert.ErrorIs(suite.T(), err, idempotency.ErrInFlight, "only the claim holder completes the key")
+	suite.Require().NoError(first.Complete(ctx, "alice k1", claim, resp, time.Hour))
+	_, stored, err = second.Begin(ctx, "alice k1", strings.Repeat("a", 64), time.Minute)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), &resp, stored)
+
+	suite.db.Exec("DELETE FROM idempotency_keys")
+	claim, _, err = first.Begin(ctx, "alice k1", strings.Repeat("a", 64), time.Minute)
+	suite.Require().NoError(err)
+	suite.Require().NoError(first.Release(ctx, "alice k1", claim))
+	_, stored, err = second.Begin(ctx, "alice k1", strings.Repeat("b", 64), time.Minute)
+	suite.Require().NoError(err)
+	assert.Nil(suite.T(), stored)
+}
+
 func TestIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(IntegrationTestSuite))
 }
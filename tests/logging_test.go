@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"qa-service/internal/database"
+	"qa-service/internal/logging"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// logBuffer collects JSON log lines written by concurrent requests.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) entries(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FormatText, "warn")
+	require.NoError(t, err)
+	logger.Info("hidden")
+	logger.WarnContext(logging.WithRequestID(context.Background(), "abc"), "shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "msg=shown request_id=abc")
+
+	_, err = logging.New(&buf, "xml", "info")
+	assert.Error(t, err)
+	_, err = logging.New(&buf, logging.FormatJSON, "loud")
+	assert.Error(t, err)
+}
+
+func TestQueryLogger(t *testing.T) {
+	logs := &logBuffer{}
+	logger, err := logging.New(logs, logging.FormatJSON, "info")
+	require.NoError(t, err)
+	queryLogger := database.NewLogger(logger, 100*time.Millisecond)
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	sql := func() (string, int64) { return `SELECT * FROM "questions"`, 3 }
+
+	// Fast queries are debug output, below the configured level.
+	queryLogger.Trace(ctx, time.Now(), sql, nil)
+	queryLogger.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+	queryLogger.Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	queryLogger.Trace(ctx, time.Now(), sql, errors.New("connection reset"))
+
+	entries := logs.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "slow query", entries[0]["msg"])
+	assert.Equal(t, "WARN", entries[0]["level"])
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, `SELECT * FROM "questions"`, entries[0]["sql"])
+	assert.EqualValues(t, 3, entries[0]["rows"])
+	assert.Equal(t, "query failed", entries[1]["msg"])
+	assert.Equal(t, "ERROR", entries[1]["level"])
+	assert.Equal(t, "connection reset", entries[1]["error"])
+}
@@ -0,0 +1,1023 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"qa-service/internal/apperr"
+	"qa-service/internal/auth"
+	"qa-service/internal/diff"
+	"qa-service/internal/handlers"
+	"qa-service/internal/health"
+	"qa-service/internal/logging"
+	"qa-service/internal/metrics"
+	"qa-service/internal/models"
+	"qa-service/internal/policy"
+	"qa-service/internal/problem"
+	"qa-service/internal/repository"
+	"qa-service/internal/routes"
+	"qa-service/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type MemoryTestSuite struct {
+	suite.Suite
+	store      *repository.MemoryStore
+	testServer *httptest.Server
+	logs       *logBuffer
+	metrics    *metrics.Metrics
+	probe      *health.Probe
+
+	handlers      routes.Handlers
+	authenticator *auth.Authenticator
+	logger        *slog.Logger
+}
+
+func (suite *MemoryTestSuite) SetupTest() {
+	suite.store = repository.NewMemoryStore()
+	suite.metrics = metrics.New()
+	suite.probe = health.NewProbe(time.Second)
+	repos := repository.NewMemoryRepositories(suite.store)
+	questionService := services.NewQuestionService(repos.Questions, repos.Answers, repos.Revisions, policy.Default(), suite.metrics)
+	answerService := services.NewAnswerService(repos.Answers, repos.Questions, repos.Revisions, policy.Default(), suite.metrics)
+	searchService := services.NewSearchService(repos.Search)
+	voteService := services.NewVoteService(repos.Votes)
+	tagService := services.NewTagService(repos.Tags)
+	apiKeyService := services.NewAPIKeyService(repos.APIKeys)
+	commentService := services.NewCommentService(repos.Comments, repos.Questions, repos.Answers, policy.Default())
+	trashService := services.NewTrashService(repos.Trash)
+
+	suite.logs = &logBuffer{}
+	logger, err := logging.New(suite.logs, logging.FormatJSON, "debug")
+	require.NoError(suite.T(), err)
+	suite.logger = logger
+	suite.handlers = routes.Handlers{
+		Questions: handlers.NewQuestionHandler(questionService, logger),
+		Answers:   handlers.NewAnswerHandler(answerService, logger),
+		Search:    handlers.NewSearchHandler(searchService, logger),
+		Votes:     handlers.NewVoteHandler(voteService, logger),
+		Tags:      handlers.NewTagHandler(tagService, logger),
+		APIKeys:   handlers.NewAPIKeyHandler(apiKeyService, logger),
+		Comments:  handlers.NewCommentHandler(commentService, logger),
+		Trash:     handlers.NewTrashHandler(trashService, logger),
+		Health:    handlers.NewHealthHandler(suite.probe, logger),
+	}
+
+	suite.authenticator = auth.NewAuthenticator(newTestJWTVerifier(), apiKeyService)
+	suite.testServer = httptest.NewServer(suite.router(routes.RateLimit{}, routes.Idempotency{}))
+}
+
+// router builds the API over the suite's store with the given rate limit
+// and idempotency settings.
+func (suite *MemoryTestSuite) router(rateLimit routes.RateLimit, idempotency routes.Idempotency) http.Handler {
+	return routes.SetupRoutes(suite.handlers, suite.authenticator, policy.Default(), routes.Timeouts{}, rateLimit, idempotency, suite.metrics, suite.logger)
+}
+
+func (suite *MemoryTestSuite) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func (suite *MemoryTestSuite) createQuestion(text string) models.Question {
+	return suite.createQuestionAs("asker", text)
+}
+
+func (suite *MemoryTestSuite) createQuestionAs(userID, text string) models.Question {
+	resp := suite.send("POST", suite.testServer.URL+"/api/v1/questions/", testToken(suite.T(), userID), map[string]string{"text": text})
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var question models.Question
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&question))
+	return question
+}
+
+func (suite *MemoryTestSuite) createAnswer(questionID uint, userID, text string) models.Answer {
+	resp := suite.send("POST", fmt.Sprintf("%s/api/v1/questions/%d/answers/", suite.testServer.URL, questionID), testToken(suite.T(), userID), map[string]string{"text": text})
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var answer models.Answer
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&answer))
+	return answer
+}
+
+func (suite *MemoryTestSuite) TestGetAllQuestionsNewestFirst() {
+	first := suite.createQuestion("Question 1?")
+	second := suite.createQuestion("Question 2?")
+
+	resp, err := http.Get(suite.testServer.URL + "/api/v1/questions/")
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var page models.QuestionPage
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&page))
+	if assert.Len(suite.T(), page.Items, 2) {
+		assert.Equal(suite.T(), second.ID, page.Items[0].ID)
+		assert.Equal(suite.T(), first.ID, page.Items[1].ID)
+	}
+}
+
+func (suite *MemoryTestSuite) listQuestions(query string) models.QuestionPage {
+	resp, err := http.Get(suite.testServer.URL + "/api/v1/questions/?" + query)
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var page models.QuestionPage
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&page))
+	return page
+}
+
+func (suite *MemoryTestSuite) TestListQuestionsPagination() {
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		ids = append(ids, suite.createQuestion(fmt.Sprintf("Question %d?", i)).ID)
+	}
+
+	var seen []uint
+	query := "limit=2&order=asc"
+	for {
+		page := suite.listQuestions(query)
+		assert.EqualValues(suite.T(), 5, page.TotalEstimate)
+		for _, question := range page.Items {
+			seen = append(seen, question.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query = "limit=2&order=asc&cursor=" + page.NextCursor
+	}
+	assert.Equal(suite.T(), ids, seen)
+
+	resp, err := http.Get(suite.testServer.URL + "/api/v1/questions/?sort=answers&cursor=" + suite.listQuestions("limit=1").NextCursor)
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestListQuestionsFilterAndSortByAnswers() {
+	unanswered := suite.createQuestion("Nobody knows?")
+	popular := suite.createQuestion("Everybody knows?")
+	answered := suite.createQuestion("Somebody knows?")
+	suite.createAnswer(popular.ID, "user1", "Yes")
+	suite.createAnswer(popular.ID, "user2", "Yes too")
+	suite.createAnswer(answered.ID, "user1", "Maybe")
+
+	page := suite.listQuestions("unanswered=true")
+	if assert.Len(suite.T(), page.Items, 1) {
+		assert.Equal(suite.T(), unanswered.ID, page.Items[0].ID)
+	}
+
+	page = suite.listQuestions("sort=answers")
+	if assert.Len(suite.T(), page.Items, 3) {
+		assert.Equal(suite.T(), popular.ID, page.Items[0].ID)
+		assert.Equal(suite.T(), 2, page.Items[0].AnswerCount)
+		assert.Equal(suite.T(), answered.ID, page.Items[1].ID)
+		assert.Equal(suite.T(), unanswered.ID, page.Items[2].ID)
+	}
+}
+
+func (suite *MemoryTestSuite) TestGetQuestionWithAnswers() {
+	question := suite.createQuestion("Which database?")
+	first := suite.createAnswer(question.ID, "user1", "Postgres")
+	second := suite.createAnswer(question.ID, "user2", "Also Postgres")
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID))
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var retrieved models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&retrieved))
+	if assert.Len(suite.T(), retrieved.Answers, 2) {
+		assert.Equal(suite.T(), first.ID, retrieved.Answers[0].ID)
+		assert.Equal(suite.T(), second.ID, retrieved.Answers[1].ID)
+	}
+}
+
+func (suite *MemoryTestSuite) TestCreateAnswerForMissingQuestion() {
+	resp := suite.send("POST", suite.testServer.URL+"/api/v1/questions/42/answers/", testToken(suite.T(), "user1"), map[string]string{"text": "Orphan"})
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	assert.Equal(suite.T(), problem.ContentType, resp.Header.Get("Content-Type"))
+
+	var details problem.Details
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&details))
+	assert.Equal(suite.T(), "question_not_found", details.Code)
+}
+
+func (suite *MemoryTestSuite) TestDeleteQuestionCascadesAnswers() {
+	question := suite.createQuestion("Question to delete")
+	answer := suite.createAnswer(question.ID, "user1", "Answer to cascade")
+
+	resp := suite.send("DELETE", fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID), testToken(suite.T(), "mod", auth.RoleModerator), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID))
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) listAnswers(questionID uint, query string) models.AnswerPage {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/questions/%d/answers/?%s", suite.testServer.URL, questionID, query))
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var page models.AnswerPage
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&page))
+	return page
+}
+
+func (suite *MemoryTestSuite) TestListAnswers() {
+	question := suite.createQuestion("How to paginate?")
+	first := suite.createAnswer(question.ID, "bob", "Use offsets")
+	second := suite.createAnswer(question.ID, "alice", "Use a cursor")
+	third := suite.createAnswer(question.ID, "bob", "Use keysets")
+
+	page := suite.listAnswers(question.ID, "limit=2&order=newest")
+	assert.EqualValues(suite.T(), 3, page.Total)
+	if assert.Len(suite.T(), page.Items, 2) {
+		assert.Equal(suite.T(), third.ID, page.Items[0].ID)
+		assert.Equal(suite.T(), second.ID, page.Items[1].ID)
+	}
+	page = suite.listAnswers(question.ID, "limit=2&order=newest&cursor="+page.NextCursor)
+	if assert.Len(suite.T(), page.Items, 1) {
+		assert.Equal(suite.T(), first.ID, page.Items[0].ID)
+	}
+	assert.Empty(suite.T(), page.NextCursor)
+
+	page = suite.listAnswers(question.ID, "order=user")
+	if assert.Len(suite.T(), page.Items, 3) {
+		assert.Equal(suite.T(), second.ID, page.Items[0].ID)
+		assert.Equal(suite.T(), first.ID, page.Items[1].ID)
+		assert.Equal(suite.T(), third.ID, page.Items[2].ID)
+	}
+
+	page = suite.listAnswers(question.ID, "user_id=bob")
+	assert.EqualValues(suite.T(), 2, page.Total)
+	assert.Len(suite.T(), page.Items, 2)
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/questions/%d?answers_limit=1", suite.testServer.URL, question.ID))
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+
+	var retrieved models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&retrieved))
+	assert.Equal(suite.T(), 3, retrieved.AnswerCount)
+	if assert.Len(suite.T(), retrieved.Answers, 1) {
+		assert.Equal(suite.T(), first.ID, retrieved.Answers[0].ID)
+	}
+	assert.NotEmpty(suite.T(), retrieved.AnswersNextCursor)
+}
+
+func (suite *MemoryTestSuite) TestSearch() {
+	question := suite.createQuestion("Как настроить пул соединений PostgreSQL?")
+	suite.createQuestion("How do I configure the HTTP router?")
+	answer := suite.createAnswer(question.ID, "user1", "Configure the connection pool with SetMaxOpenConns")
+
+	resp, err := http.Get(suite.testServer.URL + "/api/v1/search?q=connection+pool")
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var results []models.SearchResult
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&results))
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), models.SearchTypeAnswer, results[0].Type)
+		assert.Equal(suite.T(), answer.ID, results[0].ID)
+		assert.Equal(suite.T(), question.ID, results[0].QuestionID)
+		assert.Contains(suite.T(), results[0].Snippet, "<mark>connection</mark>")
+	}
+
+	resp, err = http.Get(suite.testServer.URL + "/api/v1/search?q=%D0%BF%D1%83%D0%BB&type=question")
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&results))
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), question.ID, results[0].ID)
+	}
+
+	resp, err = http.Get(suite.testServer.URL + "/api/v1/search?q=+")
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	suite.createQuestion(`Why does <script>alert("xss")</script> run?`)
+	resp, err = http.Get(suite.testServer.URL + "/api/v1/search?q=alert")
+	assert.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&results))
+	if assert.Len(suite.T(), results, 1) {
+		assert.NotContains(suite.T(), results[0].Snippet, "<script>")
+		assert.Contains(suite.T(), results[0].Snippet, "&lt;script&gt;")
+		assert.Contains(suite.T(), results[0].Snippet, "<mark>")
+	}
+}
+
+func (suite *MemoryTestSuite) TestEditQuestionWithRevisions() {
+	question := suite.createQuestion("How to rollback a migraton?")
+	questionURL := fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID)
+
+	resp := suite.send("PATCH", questionURL, testToken(suite.T(), "stranger"), map[string]string{"text": "Overwritten?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode, "only the author and staff edit a question")
+
+	editor := testToken(suite.T(), "editor", auth.RoleModerator)
+	resp = suite.send("PATCH", questionURL, editor, map[string]string{"text": "How to rollback a migration?"})
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var updated models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&updated))
+	resp.Body.Close()
+	assert.Equal(suite.T(), 2, updated.Version)
+	assert.Equal(suite.T(), "How to rollback a migration?", updated.Text)
+
+	resp, err := http.Get(questionURL + "/revisions")
+	assert.NoError(suite.T(), err)
+	var revisions []models.Revision
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&revisions))
+	resp.Body.Close()
+	if assert.Len(suite.T(), revisions, 1) {
+		assert.Equal(suite.T(), 1, revisions[0].Version)
+		assert.Equal(suite.T(), "editor", revisions[0].EditorID)
+		assert.Equal(suite.T(), "How to rollback a migraton?", revisions[0].Text)
+	}
+
+	resp, err = http.Get(questionURL + "/revisions/diff?from=1&to=2")
+	assert.NoError(suite.T(), err)
+	var result models.RevisionDiff
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&result))
+	resp.Body.Close()
+	assert.Contains(suite.T(), result.Changes, diff.Change{Op: diff.OpDelete, Text: "migraton?"})
+	assert.Contains(suite.T(), result.Changes, diff.Change{Op: diff.OpInsert, Text: "migration?"})
+
+	resp = suite.send("POST", questionURL+"/revisions/1/rollback", testToken(suite.T(), "stranger"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("POST", questionURL+"/revisions/1/rollback", editor, nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var rolledBack models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&rolledBack))
+	resp.Body.Close()
+	assert.Equal(suite.T(), 3, rolledBack.Version)
+	assert.Equal(suite.T(), question.Text, rolledBack.Text)
+
+	resp, err = http.Get(questionURL + "/revisions/diff?from=1&to=7")
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestEditAnswer() {
+	question := suite.createQuestion("Which port?")
+	answer := suite.createAnswer(question.ID, "user1", "8080")
+
+	resp := suite.send("PATCH", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID), testToken(suite.T(), "asker"), map[string]string{"text": "9090"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode, "only the author and staff edit an answer")
+
+	resp = suite.send("PATCH", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID), testToken(suite.T(), "user1"), map[string]string{"text": "8080 by default"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/answers/%d/revisions", suite.testServer.URL, answer.ID))
+	assert.NoError(suite.T(), err)
+	var revisions []models.Revision
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&revisions))
+	resp.Body.Close()
+	if assert.Len(suite.T(), revisions, 1) {
+		assert.Equal(suite.T(), "8080", revisions[0].Text)
+	}
+
+	resp = suite.send("PATCH", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID+1), testToken(suite.T(), "user1"), map[string]string{"text": "missing"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) vote(url, userID string, value int) models.VoteResult {
+	resp := suite.send("PUT", url, testToken(suite.T(), userID), map[string]int{"value": value})
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var result models.VoteResult
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+func (suite *MemoryTestSuite) TestVoting() {
+	question := suite.createQuestion("Tabs or spaces?")
+	tabs := suite.createAnswer(question.ID, "user1", "Tabs")
+	spaces := suite.createAnswer(question.ID, "user2", "Spaces")
+	answerURL := func(id uint) string { return fmt.Sprintf("%s/api/v1/answers/%d/vote", suite.testServer.URL, id) }
+
+	assert.Equal(suite.T(), 1, suite.vote(answerURL(spaces.ID), "voter1", 1).Score)
+	assert.Equal(suite.T(), 2, suite.vote(answerURL(spaces.ID), "voter2", 1).Score)
+	assert.Equal(suite.T(), 2, suite.vote(answerURL(spaces.ID), "voter2", 1).Score)
+	assert.Equal(suite.T(), 0, suite.vote(answerURL(spaces.ID), "voter2", -1).Score)
+	assert.Equal(suite.T(), 1, suite.vote(answerURL(spaces.ID), "voter2", 0).Score)
+	assert.Equal(suite.T(), -1, suite.vote(answerURL(tabs.ID), "voter1", -1).Score)
+
+	questionVote := suite.vote(fmt.Sprintf("%s/api/v1/questions/%d/vote", suite.testServer.URL, question.ID), "voter1", 1)
+	assert.Equal(suite.T(), 1, questionVote.Score)
+
+	page := suite.listAnswers(question.ID, "order=score")
+	if assert.Len(suite.T(), page.Items, 2) {
+		assert.Equal(suite.T(), spaces.ID, page.Items[0].ID)
+		assert.Equal(suite.T(), 1, page.Items[0].Score)
+		assert.Equal(suite.T(), tabs.ID, page.Items[1].ID)
+	}
+
+	resp := suite.send("PUT", answerURL(tabs.ID), testToken(suite.T(), "voter1"), map[string]int{"value": 2})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+// send issues a JSON request, authenticated with token unless it is empty.
+func (suite *MemoryTestSuite) send(method, url, token string, body interface{}) *http.Response {
+	return suite.sendWithHeaders(method, url, token, nil, body)
+}
+
+func (suite *MemoryTestSuite) sendWithHeaders(method, url, token string, headers map[string]string, body interface{}) *http.Response {
+	reqBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	return resp
+}
+
+func (suite *MemoryTestSuite) TestAcceptAnswer() {
+	question := suite.createQuestionAs("asker", "Why is my build slow?")
+	other := suite.createQuestionAs("asker", "Another question")
+	answer := suite.createAnswer(question.ID, "helper", "Enable the build cache")
+	foreign := suite.createAnswer(other.ID, "helper", "Unrelated")
+	acceptURL := fmt.Sprintf("%s/api/v1/questions/%d/accepted-answer", suite.testServer.URL, question.ID)
+
+	resp := suite.send("PUT", acceptURL, testToken(suite.T(), "helper"), map[string]uint{"answer_id": answer.ID})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("PUT", acceptURL, testToken(suite.T(), "asker"), map[string]uint{"answer_id": foreign.ID})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp = suite.send("PUT", acceptURL, testToken(suite.T(), "asker"), map[string]uint{"answer_id": answer.ID})
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var accepted models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&accepted))
+	resp.Body.Close()
+	if assert.NotNil(suite.T(), accepted.AcceptedAnswerID) {
+		assert.Equal(suite.T(), answer.ID, *accepted.AcceptedAnswerID)
+	}
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID), testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID))
+	assert.NoError(suite.T(), err)
+	var retrieved models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&retrieved))
+	resp.Body.Close()
+	assert.Nil(suite.T(), retrieved.AcceptedAnswerID)
+	assert.Equal(suite.T(), "asker", retrieved.UserID)
+}
+
+func (suite *MemoryTestSuite) createTaggedQuestion(text string, tags ...string) models.Question {
+	resp := suite.send("POST", suite.testServer.URL+"/api/v1/questions/", testToken(suite.T(), "asker"), map[string]interface{}{"text": text, "tags": tags})
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var question models.Question
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&question))
+	return question
+}
+
+func (suite *MemoryTestSuite) TestTags() {
+	both := suite.createTaggedQuestion("Connection pooling in pgx?", "Go", " PostgreSQL ")
+	goOnly := suite.createTaggedQuestion("Generics?", "go")
+	suite.createTaggedQuestion("Vacuum?", "postgresql", "DBA")
+	assert.Equal(suite.T(), []models.Tag{{Name: "go"}, {Name: "postgresql"}}, both.Tags)
+
+	page := suite.listQuestions("tag=go&tag=postgresql")
+	if assert.Len(suite.T(), page.Items, 1) {
+		assert.Equal(suite.T(), both.ID, page.Items[0].ID)
+	}
+	page = suite.listQuestions("tag=go&tag=postgresql&tag_mode=any")
+	assert.Len(suite.T(), page.Items, 3)
+
+	questionURL := fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, goOnly.ID)
+	resp := suite.send("PATCH", questionURL, testToken(suite.T(), "asker"), map[string][]string{"tags": {"go", "generics"}})
+	var edited models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&edited))
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), []string{"generics", "go"}, []string{edited.Tags[0].Name, edited.Tags[1].Name})
+	assert.Equal(suite.T(), 2, edited.Version, "a tag edit is a new version")
+
+	resp = suite.send("PATCH", questionURL, testToken(suite.T(), "asker"), map[string][]string{"tags": {"generics", "go"}})
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&edited))
+	resp.Body.Close()
+	assert.Equal(suite.T(), 2, edited.Version, "setting the same tags changes nothing")
+
+	resp, err := http.Get(questionURL + "/revisions")
+	require.NoError(suite.T(), err)
+	var revisions []models.Revision
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&revisions))
+	resp.Body.Close()
+	if assert.Len(suite.T(), revisions, 1) {
+		assert.Equal(suite.T(), "asker", revisions[0].EditorID)
+	}
+
+	admin := testToken(suite.T(), "root", auth.RoleAdmin)
+	resp = suite.send("POST", suite.testServer.URL+"/api/v1/admin/tags/postgresql/merge", admin, map[string]string{"into": "dba"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	resp = suite.send("PUT", suite.testServer.URL+"/api/v1/admin/tags/dba", admin, map[string]string{"name": "Databases"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Get(suite.testServer.URL + "/api/v1/tags")
+	assert.NoError(suite.T(), err)
+	var usage []models.TagUsage
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&usage))
+	resp.Body.Close()
+	assert.Equal(suite.T(), []models.TagUsage{
+		{Name: "databases", Count: 2},
+		{Name: "go", Count: 2},
+		{Name: "generics", Count: 1},
+	}, usage)
+
+	resp = suite.send("POST", suite.testServer.URL+"/api/v1/questions/", testToken(suite.T(), "asker"), map[string]interface{}{"text": "Bad tag", "tags": []string{"no/slashes"}})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestAuthentication() {
+	questionsURL := suite.testServer.URL + "/api/v1/questions/"
+
+	resp := suite.send("POST", questionsURL, "", map[string]string{"text": "Anonymous?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+	assert.NotEmpty(suite.T(), resp.Header.Get("WWW-Authenticate"))
+
+	resp = suite.send("POST", questionsURL, "not-a-token", map[string]string{"text": "Forged?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+
+	resp = suite.send("POST", questionsURL, signTestToken(suite.T(), jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix()}), map[string]string{"text": "Expired?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "alice"), map[string]string{"user_id": "mallory", "text": "Who am I?"})
+	var details problem.Details
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&details))
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "user_id", Rule: "unknown", Message: "unknown field"}}, details.Errors)
+
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "alice"), map[string]string{"text": "Who am I?"})
+	var question models.Question
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&question))
+	resp.Body.Close()
+	assert.Equal(suite.T(), "alice", question.UserID)
+
+	resp = suite.send("POST", suite.testServer.URL+"/api/v1/admin/api-keys", testToken(suite.T(), "alice"), map[string]string{"user_id": "alice"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("POST", suite.testServer.URL+"/api/v1/admin/api-keys", testToken(suite.T(), "root", auth.RoleAdmin), map[string]string{"user_id": "bot", "name": "importer"})
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	var key models.CreatedAPIKey
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&key))
+	resp.Body.Close()
+	assert.Equal(suite.T(), models.RoleList{auth.RoleUser}, key.Roles)
+
+	reqBody, _ := json.Marshal(map[string]string{"text": "Posted by a bot"})
+	req, _ := http.NewRequest("POST", questionsURL, bytes.NewBuffer(reqBody))
+	req.Header.Set("X-API-Key", key.Key)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&question))
+	resp.Body.Close()
+	assert.Equal(suite.T(), "bot", question.UserID)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s/api/v1/admin/api-keys/%d", suite.testServer.URL, key.ID), testToken(suite.T(), "root", auth.RoleAdmin), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp = suite.send("POST", questionsURL, key.Key, map[string]string{"text": "Still there?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestDeletePermissions() {
+	answered := suite.createQuestionAs("asker", "Answered question")
+	unanswered := suite.createQuestionAs("asker", "Unanswered question")
+	answer := suite.createAnswer(answered.ID, "helper", "An answer")
+	questionURL := func(id uint) string { return fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, id) }
+	answerURL := fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID)
+
+	resp := suite.send("DELETE", questionURL(answered.ID), testToken(suite.T(), "asker"), nil)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), "while it has no answers")
+
+	resp = suite.send("DELETE", questionURL(unanswered.ID), testToken(suite.T(), "stranger"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("DELETE", questionURL(unanswered.ID), testToken(suite.T(), "asker"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp = suite.send("DELETE", answerURL, testToken(suite.T(), "asker"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("DELETE", answerURL, testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp = suite.send("DELETE", suite.testServer.URL+"/api/v1/admin/api-keys/1", testToken(suite.T(), "mod", auth.RoleModerator), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestComments() {
+	question := suite.createQuestionAs("asker", "Is this a question?")
+	answer := suite.createAnswer(question.ID, "helper", "It is")
+	questionComments := fmt.Sprintf("%s/api/v1/questions/%d/comments/", suite.testServer.URL, question.ID)
+	answerComments := fmt.Sprintf("%s/api/v1/answers/%d/comments/", suite.testServer.URL, answer.ID)
+
+	resp := suite.send("POST", questionComments, testToken(suite.T(), "helper"), map[string]string{"text": "  Which version?  "})
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	var clarification models.Comment
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&clarification))
+	resp.Body.Close()
+	assert.Equal(suite.T(), "Which version?", clarification.Text)
+	assert.Equal(suite.T(), "helper", clarification.UserID)
+
+	resp = suite.send("POST", answerComments, testToken(suite.T(), "asker"), map[string]string{"text": "Thanks!"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	resp = suite.send("POST", answerComments, testToken(suite.T(), "asker"), map[string]string{"text": strings.Repeat("ы", models.CommentMaxLength+1)})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp = suite.send("POST", suite.testServer.URL+"/api/v1/answers/999/comments/", testToken(suite.T(), "asker"), map[string]string{"text": "Lost"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, err := http.Get(questionComments)
+	assert.NoError(suite.T(), err)
+	var comments []models.Comment
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&comments))
+	resp.Body.Close()
+	if assert.Len(suite.T(), comments, 1) {
+		assert.Equal(suite.T(), clarification.ID, comments[0].ID)
+	}
+
+	page := suite.listAnswers(question.ID, "")
+	assert.EqualValues(suite.T(), 1, page.Total)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s%d", answerComments, clarification.ID), testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s%d", questionComments, clarification.ID), testToken(suite.T(), "asker"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s%d", questionComments, clarification.ID), testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID), testToken(suite.T(), "helper"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Get(answerComments)
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	comments, err = repository.NewMemoryCommentRepository(suite.store).ListByTarget(context.Background(), models.CommentTargetAnswer, answer.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), comments, 1, "comments are kept until the answer is purged")
+}
+
+func (suite *MemoryTestSuite) TestRequestValidation() {
+	questionsURL := suite.testServer.URL + "/api/v1/questions/"
+	token := testToken(suite.T(), "asker")
+
+	invalid := func(body interface{}) problem.Details {
+		resp := suite.send("POST", questionsURL, token, body)
+		defer resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+		var details problem.Details
+		assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&details))
+		return details
+	}
+
+	details := invalid(map[string]string{"text": " \n\t "})
+	assert.Equal(suite.T(), "validation_failed", details.Code)
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "text", Rule: "required", Message: "is required"}}, details.Errors)
+
+	details = invalid(map[string]string{"text": strings.Repeat("a", 1001)})
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "text", Rule: "max", Message: "must be at most 1000 characters long"}}, details.Errors)
+
+	details = invalid(map[string]interface{}{"text": "Tagged", "tags": []string{"a", "b", "c", "d", "e", "f"}})
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "tags", Rule: "max", Message: "must have at most 5 items"}}, details.Errors)
+
+	details = invalid(map[string]interface{}{"text": 42})
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "text", Rule: "type", Message: "must be a string"}}, details.Errors)
+
+	details = invalid("not an object")
+	assert.Equal(suite.T(), "invalid_body", details.Code)
+
+	question := suite.createQuestion("  Падает сборка  ")
+	assert.Equal(suite.T(), "Падает сборка", question.Text)
+
+	resp := suite.send("PUT", fmt.Sprintf("%s/api/v1/questions/%d/vote", suite.testServer.URL, question.ID), token, map[string]int{"value": 2})
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&details))
+	assert.Equal(suite.T(), []apperr.FieldError{{Field: "value", Rule: "oneof", Message: "must be one of -1, 0, 1"}}, details.Errors)
+}
+
+func (suite *MemoryTestSuite) listTrash() []models.TrashItem {
+	resp := suite.send("GET", suite.testServer.URL+"/api/v1/admin/trash", testToken(suite.T(), "admin", auth.RoleAdmin), nil)
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var items []models.TrashItem
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&items))
+	return items
+}
+
+func (suite *MemoryTestSuite) TestTrash() {
+	question := suite.createQuestionAs("asker", "Where did it go?")
+	kept := suite.createAnswer(question.ID, "helper", "Deleted with the question")
+	alone := suite.createAnswer(question.ID, "other", "Deleted on its own")
+	modToken := testToken(suite.T(), "mod", auth.RoleModerator)
+	adminToken := testToken(suite.T(), "admin", auth.RoleAdmin)
+	questionURL := fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID)
+	restoreQuestion := fmt.Sprintf("%s/api/v1/admin/trash/questions/%d/restore", suite.testServer.URL, question.ID)
+	restoreAnswer := fmt.Sprintf("%s/api/v1/admin/trash/answers/%d/restore", suite.testServer.URL, alone.ID)
+
+	resp := suite.send("DELETE", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, alone.ID), modToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	assert.EqualValues(suite.T(), 1, suite.listAnswers(question.ID, "").Total)
+
+	resp = suite.send("DELETE", questionURL, modToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err := http.Get(questionURL)
+	assert.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	assert.Empty(suite.T(), suite.listQuestions("").Items)
+
+	items := suite.listTrash()
+	if assert.Len(suite.T(), items, 1, "answers of a deleted question are not listed separately") {
+		assert.Equal(suite.T(), models.TrashTypeQuestion, items[0].Type)
+		assert.Equal(suite.T(), question.ID, items[0].ID)
+	}
+
+	resp = suite.send("GET", suite.testServer.URL+"/api/v1/admin/trash", modToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = suite.send("POST", restoreAnswer, adminToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	resp = suite.send("POST", restoreQuestion, adminToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	page := suite.listAnswers(question.ID, "")
+	if assert.Len(suite.T(), page.Items, 1) {
+		assert.Equal(suite.T(), kept.ID, page.Items[0].ID)
+	}
+	items = suite.listTrash()
+	if assert.Len(suite.T(), items, 1) {
+		assert.Equal(suite.T(), models.TrashTypeAnswer, items[0].Type)
+		assert.Equal(suite.T(), alone.ID, items[0].ID)
+	}
+
+	resp = suite.send("POST", restoreAnswer, adminToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+	assert.EqualValues(suite.T(), 2, suite.listAnswers(question.ID, "").Total)
+
+	resp = suite.send("POST", restoreAnswer, adminToken, nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *MemoryTestSuite) TestTrashPurge() {
+	question := suite.createQuestionAs("asker", "Short lived")
+	answer := suite.createAnswer(question.ID, "helper", "Also short lived")
+	resp := suite.send("POST", fmt.Sprintf("%s/api/v1/answers/%d/comments/", suite.testServer.URL, answer.ID), testToken(suite.T(), "asker"), map[string]string{"text": "Thanks"})
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	resp = suite.send("DELETE", fmt.Sprintf("%s/api/v1/questions/%d", suite.testServer.URL, question.ID), testToken(suite.T(), "mod", auth.RoleModerator), nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	trashService := services.NewTrashService(repository.NewMemoryTrashRepository(suite.store))
+	result, err := trashService.Purge(context.Background(), time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), result.Questions, "items inside the retention window stay")
+	assert.Len(suite.T(), suite.listTrash(), 1)
+
+	result, err = trashService.Purge(context.Background(), 0)
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 1, result.Questions)
+	assert.Empty(suite.T(), suite.listTrash())
+
+	comments, err := repository.NewMemoryCommentRepository(suite.store).ListByTarget(context.Background(), models.CommentTargetAnswer, answer.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), comments)
+
+	resp = suite.send("POST", fmt.Sprintf("%s/api/v1/admin/trash/questions/%d/restore", suite.testServer.URL, question.ID), testToken(suite.T(), "admin", auth.RoleAdmin), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMemoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryTestSuite))
+}
+
+func (suite *MemoryTestSuite) TestRequestLogging() {
+	req, err := http.NewRequest("GET", suite.testServer.URL+"/api/v1/questions/999", nil)
+	require.NoError(suite.T(), err)
+	req.Header.Set("X-Request-ID", "client-id-1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	resp.Body.Close()
+	assert.Equal(suite.T(), "client-id-1", resp.Header.Get("X-Request-ID"))
+
+	var access map[string]interface{}
+	for _, entry := range suite.logs.entries(suite.T()) {
+		if entry["msg"] == "request" && entry["request_id"] == "client-id-1" {
+			access = entry
+		}
+	}
+	if assert.NotNil(suite.T(), access, "access log line") {
+		assert.Equal(suite.T(), "GET", access["method"])
+		assert.Equal(suite.T(), "/api/v1/questions/999", access["path"])
+		assert.EqualValues(suite.T(), http.StatusNotFound, access["status"])
+		assert.Greater(suite.T(), access["bytes"], float64(0))
+		assert.Contains(suite.T(), access, "duration")
+		assert.Contains(suite.T(), access, "remote_addr")
+	}
+
+	// IDs that are missing or unsafe to log are replaced with generated ones.
+	for _, id := range []string{"", strings.Repeat("x", 200), "bad id"} {
+		req, err := http.NewRequest("GET", suite.testServer.URL+"/livez", nil)
+		require.NoError(suite.T(), err)
+		req.Header.Set("X-Request-ID", id)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(suite.T(), err)
+		resp.Body.Close()
+		assert.Len(suite.T(), resp.Header.Get("X-Request-ID"), 32)
+	}
+}
+
+func scrapeMetrics(t *testing.T, baseURL string) string {
+	resp, err := http.Get(baseURL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func (suite *MemoryTestSuite) TestMetrics() {
+	question := suite.createQuestion("Counted?")
+	answer := suite.createAnswer(question.ID, "answerer", "Yes")
+	resp := suite.send("DELETE", fmt.Sprintf("%s/api/v1/answers/%d", suite.testServer.URL, answer.ID), testToken(suite.T(), "answerer"), nil)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusNoContent, resp.StatusCode)
+
+	for _, path := range []string{"/api/v1/questions/999", "/api/v1/questions/998", "/no/such/path"} {
+		resp, err := http.Get(suite.testServer.URL + path)
+		require.NoError(suite.T(), err)
+		resp.Body.Close()
+	}
+
+	body := scrapeMetrics(suite.T(), suite.testServer.URL)
+	for _, line := range []string{
+		`http_requests_total{method="POST",route="/api/v1/questions/",status="201"} 1`,
+		`http_requests_total{method="GET",route="/api/v1/questions/{id}",status="404"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="DELETE",route="/api/v1/answers/{id}"} 1`,
+		`qa_questions_created_total 1`,
+		`qa_questions_deleted_total 0`,
+		`qa_answers_created_total 1`,
+		`qa_answers_deleted_total 1`,
+	} {
+		assert.Contains(suite.T(), body, line)
+	}
+	assert.NotContains(suite.T(), body, `route="/api/v1/questions/999"`)
+}
+
+func (suite *MemoryTestSuite) TestTracing() {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(previous)
+
+	question := suite.createQuestion("Traced?")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/questions/%d/answers/", suite.testServer.URL, question.ID), strings.NewReader(`{"text": "Yes"}`))
+	require.NoError(suite.T(), err)
+	req.Header.Set("Authorization", "Bearer "+testToken(suite.T(), "answerer"))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	resp.Body.Close()
+	require.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceID {
+			spans[span.Name()] = span
+		}
+	}
+	server, ok := spans["POST /api/v1/questions/{id}/answers/"]
+	require.True(suite.T(), ok, "server span")
+	assert.Equal(suite.T(), "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(suite.T(), server.Attributes(), attribute.Int("http.response.status_code", http.StatusCreated))
+
+	service, ok := spans["AnswerService.CreateAnswer"]
+	require.True(suite.T(), ok, "service span")
+	assert.Equal(suite.T(), server.SpanContext().SpanID(), service.Parent().SpanID())
+}
+
+func (suite *MemoryTestSuite) TestProbes() {
+	get := func(path string) (int, health.Report) {
+		resp, err := http.Get(suite.testServer.URL + path)
+		require.NoError(suite.T(), err)
+		defer resp.Body.Close()
+
+		var report health.Report
+		require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	status, report := get("/livez")
+	assert.Equal(suite.T(), http.StatusOK, status)
+	assert.Equal(suite.T(), health.StatusOK, report.Status)
+
+	status, report = get("/readyz")
+	assert.Equal(suite.T(), http.StatusOK, status)
+	assert.Equal(suite.T(), health.StatusOK, report.Status)
+
+	suite.probe.Drain()
+	status, report = get("/readyz")
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, status)
+	assert.Equal(suite.T(), health.StatusDraining, report.Status)
+
+	status, _ = get("/livez")
+	assert.Equal(suite.T(), http.StatusOK, status)
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"qa-service/internal/auth"
+	"qa-service/internal/policy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"question.delete": [{"owner": true}],
+		"answer.edit": [{"owner": true}, {"roles": ["moderator"]}]
+	}`), 0o600))
+
+	p, err := policy.Load(path)
+	require.NoError(t, err)
+
+	owner := &auth.Principal{Subject: "asker", Roles: []string{auth.RoleUser}}
+	other := &auth.Principal{Subject: "other", Roles: []string{auth.RoleUser}}
+	moderator := &auth.Principal{Subject: "mod", Roles: []string{auth.RoleModerator}}
+	answered := policy.Resource{OwnerID: "asker", AnswerCount: 3}
+
+	assert.NoError(t, p.Authorize(owner, policy.ActionDeleteQuestion, answered))
+	assert.True(t, policy.IsDenied(p.Authorize(moderator, policy.ActionDeleteQuestion, answered)))
+
+	assert.NoError(t, p.Authorize(moderator, policy.ActionEditAnswer, answered))
+	err = p.Authorize(other, policy.ActionEditAnswer, answered)
+	assert.True(t, policy.IsDenied(err))
+	assert.EqualError(t, err, "not allowed to edit this answer: requires being the author, or the moderator role")
+
+	assert.NoError(t, p.Authorize(owner, policy.ActionDeleteAnswer, answered))
+	assert.True(t, policy.IsDenied(p.Authorize(nil, policy.ActionEditQuestion, answered)))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"question.delete": [{"conditions": ["on_fridays"]}]}`), 0o600))
+	_, err = policy.Load(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"question.archive": [{}]}`), 0o600))
+	_, err = policy.Load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"qa-service/internal/apperr"
+	"qa-service/internal/policy"
+	"qa-service/internal/problem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemResponses(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"coded", apperr.NotFound("question_not_found", "question not found"), http.StatusNotFound, "question_not_found", "question not found"},
+		{"wrapped", fmt.Errorf("loading: %w", apperr.Conflict("tag_exists", "tag already exists")), http.StatusConflict, "tag_exists", "tag already exists"},
+		{"bare kind", apperr.ErrNotFound, http.StatusNotFound, "not_found", "not found"},
+		{"denied", &policy.DeniedError{Action: policy.ActionAdmin, Reason: "requires the admin role"}, http.StatusForbidden, "forbidden", "requires the admin role"},
+		{"unavailable", apperr.Unavailable(&net.OpError{Op: "dial", Err: errors.New("connection refused")}), http.StatusServiceUnavailable, "unavailable", "service temporarily unavailable"},
+		{"timeout", apperr.Timeout(context.DeadlineExceeded), http.StatusGatewayTimeout, "timeout", "request took too long to process"},
+		{"internal", errors.New(`pq: relation "questions" does not exist`), http.StatusInternalServerError, "internal_error", "internal server error"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			problem.Write(recorder, httptest.NewRequest("GET", "/api/v1/questions/1", nil), tc.err)
+
+			assert.Equal(t, tc.status, recorder.Code)
+			assert.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+			var details problem.Details
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&details))
+			assert.Equal(t, tc.status, details.Status)
+			assert.Equal(t, tc.code, details.Code)
+			assert.Equal(t, tc.detail, details.Detail)
+			assert.Equal(t, http.StatusText(tc.status), details.Title)
+			assert.Equal(t, "/api/v1/questions/1", details.Instance)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"qa-service/internal/ratelimit"
+	"qa-service/internal/routes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	for spec, want := range map[string]ratelimit.Policy{
+		"10/m:5":    {Limit: 10, Period: time.Minute, Burst: 5},
+		"100/10s":   {Limit: 100, Period: 10 * time.Second, Burst: 100},
+		" 1 / h ":   {Limit: 1, Period: time.Hour, Burst: 1},
+		"5/1m30s:2": {Limit: 5, Period: 90 * time.Second, Burst: 2},
+	} {
+		p, err := ratelimit.ParsePolicy(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, p, spec)
+
+		again, err := ratelimit.ParsePolicy(p.String())
+		require.NoError(t, err, p.String())
+		assert.Equal(t, p, again, "String is read back by ParsePolicy")
+	}
+	assert.Equal(t, "10/m:5", ratelimit.Policy{Limit: 10, Period: time.Minute, Burst: 5}.String())
+
+	for _, spec := range []string{"", "10", "10/", "0/m", "-1/m", "10/soon", "10/-1s", "10/m:0", "10/m:x"} {
+		_, err := ratelimit.ParsePolicy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore(func() time.Time { return now })
+	policy := ratelimit.Policy{Limit: 6, Period: time.Minute, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "alice", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "alice", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "the burst is used up")
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+	assert.Equal(t, 30*time.Second, result.Reset)
+
+	other, err := store.Take(ctx, "bob", policy)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "every key has its own bucket")
+
+	now = now.Add(10 * time.Second)
+	result, err = store.Take(ctx, "alice", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "a token is added every 10 seconds")
+	assert.Equal(t, 0, result.Remaining)
+
+	now = now.Add(time.Hour)
+	result, err = store.Take(ctx, "alice", policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining, "refilling stops at the burst")
+}
+
+func (suite *MemoryTestSuite) rateLimitedServer(trustedProxies []netip.Prefix, policies map[string]ratelimit.Policy) *httptest.Server {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(nil), policies)
+	server := httptest.NewServer(suite.router(routes.RateLimit{Limiter: limiter, TrustedProxies: trustedProxies}, routes.Idempotency{}))
+	suite.T().Cleanup(server.Close)
+	return server
+}
+
+func (suite *MemoryTestSuite) TestRateLimit() {
+	server := suite.rateLimitedServer(nil, map[string]ratelimit.Policy{
+		ratelimit.GroupRead:   {Limit: 2, Period: time.Minute, Burst: 2},
+		ratelimit.GroupCreate: {Limit: 1, Period: time.Hour, Burst: 1},
+	})
+	questionsURL := server.URL + "/api/v1/questions/"
+
+	resp := suite.send("GET", questionsURL, "", nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), "2", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(suite.T(), "1", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(suite.T(), "30", resp.Header.Get("RateLimit-Reset"))
+	assert.Equal(suite.T(), "2;w=60;burst=2", resp.Header.Get("RateLimit-Policy"))
+
+	resp = suite.send("GET", questionsURL, "", nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp = suite.send("GET", questionsURL, "", nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(suite.T(), "application/problem+json", resp.Header.Get("Content-Type"))
+	assert.Equal(suite.T(), "0", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(suite.T(), "30", resp.Header.Get("Retry-After"))
+	assert.Contains(suite.T(), scrapeMetrics(suite.T(), server.URL), `qa_rate_limited_requests_total{group="read"} 1`)
+
+	resp = suite.send("GET", questionsURL, testToken(suite.T(), "alice"), nil)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, "signed-in users are limited on their own")
+
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "alice"), map[string]string{"text": "First?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "alice"), map[string]string{"text": "Second?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(suite.T(), "3600", resp.Header.Get("Retry-After"))
+
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "bob"), map[string]string{"text": "Mine?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	resp = suite.send("PUT", questionsURL+"1", testToken(suite.T(), "alice"), map[string]string{"text": "First, edited?"})
+	resp.Body.Close()
+	assert.NotEqual(suite.T(), http.StatusTooManyRequests, resp.StatusCode, "writes without a policy are not limited")
+	assert.Empty(suite.T(), resp.Header.Get("RateLimit-Limit"))
+}
+
+func (suite *MemoryTestSuite) TestRateLimitForwardedFor() {
+	policies := map[string]ratelimit.Policy{ratelimit.GroupRead: {Limit: 1, Period: time.Minute, Burst: 1}}
+	get := func(server *httptest.Server, forwardedFor string) int {
+		req, _ := http.NewRequest("GET", server.URL+"/api/v1/questions/", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(suite.T(), err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The test client connects from loopback, standing in for the proxy.
+	trusted := suite.rateLimitedServer([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("10.0.0.0/8")}, policies)
+	assert.Equal(suite.T(), http.StatusOK, get(trusted, "203.0.113.1"))
+	assert.Equal(suite.T(), http.StatusOK, get(trusted, "203.0.113.2"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, get(trusted, "203.0.113.1"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, get(trusted, "198.51.100.7, 203.0.113.2, 10.1.2.3"),
+		"the client is the rightmost address that is not a trusted proxy")
+
+	untrusted := suite.rateLimitedServer(nil, policies)
+	assert.Equal(suite.T(), http.StatusOK, get(untrusted, "203.0.113.1"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, get(untrusted, "203.0.113.2"), "X-Forwarded-For of untrusted peers is ignored")
+}
+
+func (suite *MemoryTestSuite) TestRateLimitFailedAuthentication() {
+	server := suite.rateLimitedServer(nil, map[string]ratelimit.Policy{
+		ratelimit.GroupCreate: {Limit: 2, Period: time.Hour, Burst: 2},
+	})
+	questionsURL := server.URL + "/api/v1/questions/"
+
+	for i := 0; i < 2; i++ {
+		resp := suite.send("POST", questionsURL, "not-a-token", map[string]string{"text": "Guessing?"})
+		resp.Body.Close()
+		assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+	}
+	resp := suite.send("POST", questionsURL, "not-a-token", map[string]string{"text": "Guessing?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusTooManyRequests, resp.StatusCode, "failed authentications use up the address's bucket")
+	assert.Equal(suite.T(), "1800", resp.Header.Get("Retry-After"))
+
+	resp = suite.send("POST", questionsURL, testToken(suite.T(), "alice"), map[string]string{"text": "Signed in?"})
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode, "valid credentials are limited per user")
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"qa-service/internal/models"
+	"qa-service/internal/schemacheck"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// assertNoSchemaDrift fails t with every difference between the models and
+// the schema of db.
+func assertNoSchemaDrift(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	drifts, err := schemacheck.Check(context.Background(), db, models.All()...)
+	require.NoError(t, err)
+	for _, drift := range drifts {
+		t.Errorf("schema drift: %s", drift)
+	}
+}
+
+func TestExpectedSchema(t *testing.T) {
+	// The dialect alone is enough to derive the schema, no connection is made.
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	tables, err := schemacheck.Expected(db, models.All()...)
+	require.NoError(t, err)
+
+	byName := map[string]schemacheck.Table{}
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+	require.Contains(t, byName, "question_tags", "many2many join tables are included")
+
+	answers := byName["answers"]
+	assert.Contains(t, answers.Columns, schemacheck.Column{Name: "id", Type: "integer"})
+	assert.Contains(t, answers.Columns, schemacheck.Column{Name: "user_id", Type: "character varying(255)"})
+	assert.Contains(t, answers.Columns, schemacheck.Column{Name: "deleted_at", Type: "timestamp with time zone"})
+	assert.Contains(t, answers.Indexes, schemacheck.Index{Name: "idx_answers_deleted_at", Columns: []string{"deleted_at"}})
+	assert.Equal(t, []schemacheck.ForeignKey{
+		{Columns: []string{"question_id"}, References: "questions", OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}, answers.ForeignKeys)
+
+	assert.Len(t, byName["question_tags"].ForeignKeys, 2)
+	assert.Contains(t, byName["tags"].Indexes, schemacheck.Index{Name: "idx_tags_name", Columns: []string{"name"}, Unique: true})
+}
+
+func TestCompareSchema(t *testing.T) {
+	expected := []schemacheck.Table{
+		{
+			Name: "answers",
+			Columns: []schemacheck.Column{
+				{Name: "id", Type: "integer"},
+				{Name: "user_id", Type: "text"},
+				{Name: "score", Type: "integer"},
+			},
+			Indexes: []schemacheck.Index{
+				{Name: "idx_answers_user_id", Columns: []string{"user_id"}},
+				{Name: "idx_answers_score", Columns: []string{"score"}, Unique: true},
+			},
+			ForeignKeys: []schemacheck.ForeignKey{
+				{Columns: []string{"question_id"}, References: "questions", OnDelete: "NO ACTION", OnUpdate: "NO ACTION"},
+				{Columns: []string{"user_id"}, References: "users", OnDelete: "NO ACTION", OnUpdate: "NO ACTION"},
+			},
+		},
+		{Name: "comments"},
+	}
+	live := []schemacheck.Table{
+		{
+			Name: "answers",
+			Columns: []schemacheck.Column{
+				{Name: "id", Type: "integer"},
+				{Name: "user_id", Type: "character varying(255)"},
+				{Name: "question_id", Type: "integer"},
+				{Name: "search_vector", Type: "tsvector", Generated: true},
+			},
+			Indexes: []schemacheck.Index{
+				{Name: "answers_user_id_key", Columns: []string{"user_id"}, Unique: true},
+				{Name: "idx_answers_score", Columns: []string{"score"}},
+			},
+			ForeignKeys: []schemacheck.ForeignKey{
+				{Columns: []string{"question_id"}, References: "questions", OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+			},
+		},
+	}
+
+	var got []string
+	for _, drift := range schemacheck.Compare(expected, live) {
+		got = append(got, drift.Kind+" "+drift.String())
+	}
+	assert.Equal(t, []string{
+		"type_mismatch answers: column user_id is character varying(255), models expect text",
+		"missing_column answers: column score integer is missing",
+		"extra_column answers: column question_id integer is not mapped by any model",
+		"missing_index answers: unique index idx_answers_score on (score) is missing",
+		"foreign_key_action answers: foreign key (question_id) to questions is ON DELETE CASCADE ON UPDATE NO ACTION, models expect ON DELETE NO ACTION ON UPDATE NO ACTION",
+		"missing_foreign_key answers: foreign key (user_id) to users is missing",
+		"missing_table comments: table is missing",
+	}, got)
+}
+
+func TestNormalizeType(t *testing.T) {
+	for in, want := range map[string]string{
+		"serial":                      "integer",
+		"bigserial":                   "bigint",
+		"varchar(255)":                "character varying(255)",
+		"VARCHAR (255)":               "character varying(255)",
+		"char":                        "character(1)",
+		"char(64)":                    "character(64)",
+		"timestamptz":                 "timestamp with time zone",
+		"timestamptz(3)":              "timestamp(3) with time zone",
+		"timestamp(3) with time zone": "timestamp(3) with time zone",
+		"numeric(10, 2)":              "numeric(10,2)",
+		"double precision":            "double precision",
+		"tsvector":                    "tsvector",
+	} {
+		assert.Equal(t, want, schemacheck.NormalizeType(in), in)
+	}
+}